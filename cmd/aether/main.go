@@ -18,6 +18,9 @@ import (
 
 const version = "0.1.0"
 
+// shutdownGracePeriod bounds how long an interrupted run waits for in-flight tasks.
+const shutdownGracePeriod = 5 * time.Second
+
 func main() {
 	// 1. Initialize nanosecond precision telemetry before any allocations
 	core.InitTelemetry()
@@ -116,16 +119,22 @@ func handleRunCmd(args []string, kernelMode bool) {
 	targetTool := runCmd.String("tool", "", "Bypass LLM and execute a specific native tool directly")
 	toolArgs := runCmd.String("args", "{}", "JSON arguments to pass to the target tool")
 	workerCount := runCmd.Int("workers", 4, "Number of concurrent event loop workers")
+	maxWorkers := runCmd.Int("max-workers", 0, "Autoscale the worker pool between --workers and this many workers (0 disables)")
 	sandboxPubkey := runCmd.String("pubkey", "", "Path to authorized Ed25519 public key manifest")
+	approvalTimeout := runCmd.Duration("approval-timeout", 2*time.Minute, "Deny network/filesystem tool calls not approved on the terminal within this time")
 
 	if err := runCmd.Parse(args); err != nil {
 		core.Logger().Error("failed_to_parse_run_flags", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
 
-	_ = workerCount
 	_ = sandboxPubkey
 
+	if *workerCount < 1 {
+		fmt.Println("Error: --workers must be at least 1")
+		os.Exit(1)
+	}
+
 	if *targetTool != "" {
 		runToolNative(*targetTool, *toolArgs)
 		return
@@ -134,10 +143,13 @@ func handleRunCmd(args []string, kernelMode bool) {
 		fmt.Println("Error: --goal is required for 'run' if not specifying a --tool")
 		os.Exit(1)
 	}
-	runPicoMode(*goal, kernelMode)
+	pool := core.AutoscaleConfig{Min: *workerCount, Max: *maxWorkers}
+	runPicoMode(*goal, kernelMode, *approvalTimeout, pool)
 }
 
-func runPicoMode(goal string, isKernel bool) {
+// runPicoMode runs a single goal. pool.Min is the initial worker count; a
+// pool.Max above it enables autoscaling.
+func runPicoMode(goal string, isKernel bool, approvalTimeout time.Duration, pool core.AutoscaleConfig) {
 	core.Logger().Debug("validating_authentication")
 	manager, err := core.NewAuthManager(nil)
 	if err != nil {
@@ -162,7 +174,12 @@ func runPicoMode(goal string, isKernel bool) {
 	start := time.Now()
 
 	adapter := core.NewMockOllamaAdapter()
-	engine := core.NewEngine(adapter, 4, 100)
+	policy := core.DefaultApprovalPolicy()
+	policy.Timeout = approvalTimeout
+	engine := core.NewEngine(adapter, pool.Min, 100).WithApprover(core.NewTerminalApprover(os.Stdin, os.Stderr), policy)
+	if pool.Max > pool.Min {
+		engine.WithAutoscaling(pool)
+	}
 	if err := engine.RegisterTool(&tools.SysInfoTool{}); err != nil {
 		core.Logger().Error("tool_registration_failed", slog.String("tool", "sys_info"), slog.String("error", err.Error()))
 		os.Exit(1)
@@ -191,8 +208,10 @@ func runPicoMode(goal string, isKernel bool) {
 		engine.Stop()
 	case <-sigChan:
 		core.Logger().Warn("os_interrupt_received", slog.String("action", "shutting_down_worker_pool"))
-		engine.Stop()
-		core.Logger().Info("shutdown_complete", slog.Duration("uptime", time.Since(start)))
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownGracePeriod)
+		report, _ := engine.Shutdown(ctx)
+		cancel()
+		core.Logger().Info("shutdown_complete", slog.Duration("uptime", time.Since(start)), slog.Int("abandoned_tasks", len(report.Abandoned)))
 		os.Exit(130)
 	}
 
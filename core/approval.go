@@ -0,0 +1,188 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+// ErrToolCallDenied is returned to the model when an approver rejects a call.
+var ErrToolCallDenied = errors.New("tool call denied by approver")
+
+// defaultApprovalTimeout bounds how long a call may wait for a decision
+// before it is denied.
+const defaultApprovalTimeout = 2 * time.Minute
+
+// ApprovalVerdict is an approver's decision on a tool call.
+type ApprovalVerdict string
+
+const (
+	ApprovalApproved ApprovalVerdict = "approved"
+	ApprovalDenied   ApprovalVerdict = "denied"
+	ApprovalEdited   ApprovalVerdict = "edited" // approved with replacement arguments
+)
+
+// ApprovalRequest describes a tool call awaiting a decision.
+type ApprovalRequest struct {
+	TaskID       string
+	Iteration    int
+	Tool         string
+	Arguments    string
+	Capabilities []llm.Capability
+}
+
+// ApprovalDecision is the answer to an ApprovalRequest. Reason is reported to
+// the model on denial; Arguments replaces the call's arguments when Verdict is
+// ApprovalEdited.
+type ApprovalDecision struct {
+	Verdict   ApprovalVerdict
+	Reason    string
+	Arguments string
+}
+
+// Approver decides whether a sensitive tool call may run. Approve should honour
+// ctx; the engine denies the call once the policy timeout expires regardless.
+type Approver interface {
+	Approve(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error)
+}
+
+// ApproverFunc adapts a function to the Approver interface.
+type ApproverFunc func(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error)
+
+// Approve calls f.
+func (f ApproverFunc) Approve(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error) {
+	return f(ctx, req)
+}
+
+// ApprovalPolicy selects the tool calls that require approval. A call matches
+// if its tool is listed in Tools, its manifest declares any of Capabilities,
+// or its raw JSON arguments match any of ArgumentPatterns.
+type ApprovalPolicy struct {
+	Tools            []string
+	Capabilities     []llm.Capability
+	ArgumentPatterns []*regexp.Regexp
+	Timeout          time.Duration // zero means defaultApprovalTimeout
+}
+
+// DefaultApprovalPolicy gates every tool declaring network or filesystem access.
+func DefaultApprovalPolicy() *ApprovalPolicy {
+	return &ApprovalPolicy{Capabilities: []llm.Capability{llm.CapNetwork, llm.CapFilesystem}}
+}
+
+// requires reports whether a call matches the policy.
+func (p *ApprovalPolicy) requires(name string, caps []llm.Capability, args string) bool {
+	if slices.Contains(p.Tools, name) {
+		return true
+	}
+	for _, c := range caps {
+		if slices.Contains(p.Capabilities, c) {
+			return true
+		}
+	}
+	for _, re := range p.ArgumentPatterns {
+		if re.MatchString(args) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *ApprovalPolicy) timeout() time.Duration {
+	if p.Timeout > 0 {
+		return p.Timeout
+	}
+	return defaultApprovalTimeout
+}
+
+// approvalGate pairs an approver with the policy deciding when to consult it.
+type approvalGate struct {
+	approver Approver
+	policy   *ApprovalPolicy
+}
+
+// WithApprover requires approval for tool calls matching policy before they
+// are dispatched. A nil policy means DefaultApprovalPolicy. Denied calls are
+// reported to the model as tool errors, and every decision is written to the
+// audit chain.
+func (e *Engine) WithApprover(a Approver, policy *ApprovalPolicy) *Engine {
+	if policy == nil {
+		policy = DefaultApprovalPolicy()
+	}
+	e.approval = &approvalGate{approver: a, policy: policy}
+	return e
+}
+
+// approveToolCall is the built-in interceptor enforcing the approval gate.
+func (e *Engine) approveToolCall(ctx context.Context, inv *ToolInvocation, next ToolHandler) (string, error) {
+	if e.approval == nil {
+		return next(ctx, inv)
+	}
+
+	var caps []llm.Capability
+	if tool, err := e.tools.Get(inv.Call.Name); err == nil {
+		caps = tool.Manifest().Capabilities
+	}
+	if !e.approval.policy.requires(inv.Call.Name, caps, inv.Call.Arguments) {
+		return next(ctx, inv)
+	}
+
+	decision := e.approval.decide(ctx, ApprovalRequest{
+		TaskID: inv.TaskID, Iteration: inv.Iteration, Tool: inv.Call.Name, Arguments: inv.Call.Arguments, Capabilities: caps,
+	})
+	e.logAudit(ctx, inv.TaskID+"-tool-approval", "AUDIT_TOOL_APPROVAL", "approver", map[string]interface{}{
+		"task_id": inv.TaskID, "tool": inv.Call.Name, "verdict": string(decision.Verdict), "reason": decision.Reason,
+	})
+	WithTask(ctx, inv.TaskID).Info("tool_approval_decided",
+		slog.String("tool", inv.Call.Name),
+		slog.String("verdict", string(decision.Verdict)),
+	)
+
+	switch decision.Verdict {
+	case ApprovalApproved:
+		return next(ctx, inv)
+	case ApprovalEdited:
+		inv.Call.Arguments = decision.Arguments
+		return next(ctx, inv)
+	default:
+		return "", fmt.Errorf("%w: %s", ErrToolCallDenied, decision.Reason)
+	}
+}
+
+// decide consults the approver under the policy timeout. Errors, timeouts and
+// unknown verdicts all resolve to a denial.
+func (g *approvalGate) decide(ctx context.Context, req ApprovalRequest) ApprovalDecision {
+	ctx, cancel := context.WithTimeout(ctx, g.policy.timeout())
+	defer cancel()
+
+	type outcome struct {
+		d   ApprovalDecision
+		err error
+	}
+	ch := make(chan outcome, 1)
+	go func() {
+		d, err := g.approver.Approve(ctx, req)
+		ch <- outcome{d, err}
+	}()
+
+	select {
+	case o := <-ch:
+		switch {
+		case o.err != nil:
+			return ApprovalDecision{Verdict: ApprovalDenied, Reason: "approver error: " + o.err.Error()}
+		case o.d.Verdict == ApprovalApproved, o.d.Verdict == ApprovalEdited, o.d.Verdict == ApprovalDenied:
+			return o.d
+		default:
+			return ApprovalDecision{Verdict: ApprovalDenied, Reason: fmt.Sprintf("unknown verdict %q", o.d.Verdict)}
+		}
+	case <-ctx.Done():
+		return ApprovalDecision{Verdict: ApprovalDenied, Reason: "approval timed out"}
+	}
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+// TerminalApprover prompts an operator on a terminal for each gated tool call.
+// Answers are "y" to approve, "n [reason]" to deny and "e <json>" to approve
+// with edited arguments. Prompts are serialised, so concurrent tool calls are
+// asked about one at a time; an unanswered prompt is denied when its context
+// expires.
+type TerminalApprover struct {
+	out io.Writer
+
+	mu      sync.Mutex // serialises prompts
+	expired bool       // the previous prompt went unanswered; guarded by mu
+	epoch   atomic.Uint64
+	once    sync.Once
+	in      *bufio.Scanner
+	lines   chan answerLine
+}
+
+// answerLine is one input line tagged with the epoch in which it was read.
+type answerLine struct {
+	text  string
+	epoch uint64
+}
+
+// NewTerminalApprover reads answers from in and writes prompts to out.
+func NewTerminalApprover(in io.Reader, out io.Writer) *TerminalApprover {
+	return &TerminalApprover{in: bufio.NewScanner(in), out: out, lines: make(chan answerLine)}
+}
+
+// readLines feeds input lines to Approve. A single long-lived reader ensures
+// that a line typed after a prompt timed out is not stolen by a stale read.
+func (a *TerminalApprover) readLines() {
+	for a.in.Scan() {
+		a.lines <- a.tag(a.in.Text())
+	}
+	close(a.lines)
+}
+
+// tag stamps a freshly read line with the current epoch. The epoch advances
+// when a prompt expires and again when the next prompt opens, so a late answer
+// to an expired prompt can never approve a later one.
+func (a *TerminalApprover) tag(text string) answerLine {
+	return answerLine{text: text, epoch: a.epoch.Load()}
+}
+
+// Approve implements Approver.
+func (a *TerminalApprover) Approve(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error) {
+	a.once.Do(func() { go a.readLines() })
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.expired {
+		a.epoch.Add(1)
+		a.expired = false
+	}
+	epoch := a.epoch.Load()
+
+	fmt.Fprintf(a.out, "\n[approval] task %s wants to run %s", req.TaskID, req.Tool)
+	if len(req.Capabilities) > 0 {
+		caps := make([]string, len(req.Capabilities))
+		for i, c := range req.Capabilities {
+			caps[i] = string(c)
+		}
+		fmt.Fprintf(a.out, " (capabilities: %s)", strings.Join(caps, ", "))
+	}
+	fmt.Fprintf(a.out, "\n  arguments: %s\n", req.Arguments)
+
+	for {
+		fmt.Fprint(a.out, "  approve? [y]es / [n]o [reason] / [e]dit <json>: ")
+		line, ok, err := a.next(ctx, epoch)
+		if err != nil {
+			a.epoch.Add(1)
+			a.expired = true
+			fmt.Fprintln(a.out, "\n  no answer in time; denied")
+			return ApprovalDecision{}, err
+		}
+		if !ok {
+			return ApprovalDecision{Verdict: ApprovalDenied, Reason: "approval input closed"}, nil
+		}
+		if d, valid := parseApprovalAnswer(line); valid {
+			return d, nil
+		}
+		fmt.Fprintln(a.out, "  unrecognised answer")
+	}
+}
+
+// next returns the next line read in epoch, discarding late answers to
+// earlier prompts. ok is false once the input is closed.
+func (a *TerminalApprover) next(ctx context.Context, epoch uint64) (line string, ok bool, err error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return "", false, ctx.Err()
+		case l, open := <-a.lines:
+			if !open {
+				return "", false, nil
+			}
+			if l.epoch == epoch {
+				return l.text, true, nil
+			}
+		}
+	}
+}
+
+// parseApprovalAnswer interprets one line typed by the operator.
+func parseApprovalAnswer(line string) (ApprovalDecision, bool) {
+	line = strings.TrimSpace(line)
+	cmd, rest, _ := strings.Cut(line, " ")
+	rest = strings.TrimSpace(rest)
+	switch strings.ToLower(cmd) {
+	case "y", "yes":
+		return ApprovalDecision{Verdict: ApprovalApproved}, true
+	case "n", "no":
+		if rest == "" {
+			rest = "denied by operator"
+		}
+		return ApprovalDecision{Verdict: ApprovalDenied, Reason: rest}, true
+	case "e", "edit":
+		if !json.Valid([]byte(rest)) {
+			return ApprovalDecision{}, false
+		}
+		return ApprovalDecision{Verdict: ApprovalEdited, Arguments: rest}, true
+	}
+	return ApprovalDecision{}, false
+}
@@ -0,0 +1,229 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+// netTool declares CapNetwork and records the arguments it ran with.
+type netTool struct {
+	mu   sync.Mutex
+	args []string
+}
+
+func (n *netTool) Manifest() llm.ToolManifest {
+	return llm.ToolManifest{Name: "fetch", Capabilities: []llm.Capability{llm.CapNetwork}}
+}
+
+func (n *netTool) Execute(_ context.Context, args string) (string, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.args = append(n.args, args)
+	return "fetched", nil
+}
+
+func (n *netTool) calls() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string(nil), n.args...)
+}
+
+func fetchThenDone() *scriptedLLM {
+	return &scriptedLLM{responses: []llm.LLMResponse{
+		{ToolCalls: []llm.ToolCall{{ID: "c1", Name: "fetch", Arguments: `{"url":"http://a"}`}}},
+		{Content: "done"},
+	}}
+}
+
+// lastToolResult returns the tool message the model saw on its final request.
+func lastToolResult(t *testing.T, adapter *scriptedLLM) llm.ToolResultMessage {
+	t.Helper()
+	req := adapter.requests[len(adapter.requests)-1]
+	return req[len(req)-1].ToolResults[0]
+}
+
+func TestApprover_denyIsReportedAndAudited(t *testing.T) {
+	adapter := fetchThenDone()
+	tool := &netTool{}
+	al := &MockAuditLogger{}
+	deny := ApproverFunc(func(_ context.Context, req ApprovalRequest) (ApprovalDecision, error) {
+		if req.Tool != "fetch" || len(req.Capabilities) != 1 {
+			t.Errorf("unexpected request %+v", req)
+		}
+		return ApprovalDecision{Verdict: ApprovalDenied, Reason: "no outbound traffic"}, nil
+	})
+	engine := NewEngine(adapter, 1, 1).WithAuditLogger(al).WithApprover(deny, nil)
+	registerAll(t, engine, tool)
+
+	if res := runSingleTask(t, engine, &Task{ID: "deny", Input: "go"}); res.Error != nil {
+		t.Fatalf("denial must not fail the task: %v", res.Error)
+	}
+	if len(tool.calls()) != 0 {
+		t.Error("denied tool must not run")
+	}
+	if got := lastToolResult(t, adapter); !got.IsError || !strings.Contains(got.Content, "no outbound traffic") {
+		t.Errorf("model should see the denial reason, got %+v", got)
+	}
+
+	found := false
+	for _, ev := range al.Events {
+		if ev.Type == "AUDIT_TOOL_APPROVAL" && ev.Metadata["verdict"] == string(ApprovalDenied) {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected AUDIT_TOOL_APPROVAL event")
+	}
+}
+
+func TestApprover_editReplacesArguments(t *testing.T) {
+	tool := &netTool{}
+	edit := ApproverFunc(func(_ context.Context, _ ApprovalRequest) (ApprovalDecision, error) {
+		return ApprovalDecision{Verdict: ApprovalEdited, Arguments: `{"url":"http://safe"}`}, nil
+	})
+	engine := NewEngine(fetchThenDone(), 1, 1).WithApprover(edit, nil)
+	registerAll(t, engine, tool)
+
+	runSingleTask(t, engine, &Task{ID: "edit", Input: "go"})
+	if got := tool.calls(); len(got) != 1 || got[0] != `{"url":"http://safe"}` {
+		t.Fatalf("tool should run with edited arguments, got %v", got)
+	}
+}
+
+func TestApprover_seesArgumentsRewrittenByInterceptors(t *testing.T) {
+	tool := &netTool{}
+	var approved string
+	approve := ApproverFunc(func(_ context.Context, req ApprovalRequest) (ApprovalDecision, error) {
+		approved = req.Arguments
+		return ApprovalDecision{Verdict: ApprovalApproved}, nil
+	})
+	engine := NewEngine(fetchThenDone(), 1, 1).WithApprover(approve, nil).UseToolInterceptors(
+		func(ctx context.Context, inv *ToolInvocation, next ToolHandler) (string, error) {
+			inv.Call.Arguments = `{"url":"http://b"}`
+			return next(ctx, inv)
+		},
+	)
+	registerAll(t, engine, tool)
+
+	runSingleTask(t, engine, &Task{ID: "rewrite", Input: "go"})
+	if got := tool.calls(); approved != `{"url":"http://b"}` || len(got) != 1 || got[0] != approved {
+		t.Fatalf("approver saw %q but the tool ran with %v", approved, got)
+	}
+}
+
+func TestApprover_timeoutDenies(t *testing.T) {
+	adapter := fetchThenDone()
+	tool := &netTool{}
+	release := make(chan struct{})
+	defer close(release)
+	stuck := ApproverFunc(func(_ context.Context, _ ApprovalRequest) (ApprovalDecision, error) {
+		<-release // ignores ctx on purpose
+		return ApprovalDecision{Verdict: ApprovalApproved}, nil
+	})
+	engine := NewEngine(adapter, 1, 1).WithApprover(stuck, &ApprovalPolicy{Tools: []string{"fetch"}, Timeout: 20 * time.Millisecond})
+	registerAll(t, engine, tool)
+
+	runSingleTask(t, engine, &Task{ID: "timeout", Input: "go"})
+	if len(tool.calls()) != 0 {
+		t.Error("timed-out approval must not run the tool")
+	}
+	if got := lastToolResult(t, adapter); !strings.Contains(got.Content, "approval timed out") {
+		t.Errorf("want timeout denial, got %+v", got)
+	}
+}
+
+func TestApprover_policyMatching(t *testing.T) {
+	p := &ApprovalPolicy{
+		Tools:            []string{"shell"},
+		Capabilities:     []llm.Capability{llm.CapFilesystem},
+		ArgumentPatterns: []*regexp.Regexp{regexp.MustCompile(`rm -rf`)},
+	}
+	cases := []struct {
+		name string
+		caps []llm.Capability
+		args string
+		want bool
+	}{
+		{"shell", nil, "{}", true},
+		{"read", []llm.Capability{llm.CapFilesystem}, "{}", true},
+		{"run", nil, `{"cmd":"rm -rf /"}`, true},
+		{"sys_info", []llm.Capability{llm.CapState}, "{}", false},
+	}
+	for _, c := range cases {
+		if got := p.requires(c.name, c.caps, c.args); got != c.want {
+			t.Errorf("requires(%s) = %v, want %v", c.name, got, c.want)
+		}
+	}
+
+	consulted := false
+	never := ApproverFunc(func(_ context.Context, _ ApprovalRequest) (ApprovalDecision, error) {
+		consulted = true
+		return ApprovalDecision{Verdict: ApprovalDenied}, nil
+	})
+	engine := NewEngine(&scriptedLLM{responses: []llm.LLMResponse{
+		{ToolCalls: []llm.ToolCall{{ID: "c1", Name: "count"}}},
+		{Content: "done"},
+	}}, 1, 1).WithApprover(never, nil)
+	tool := &countingTool{name: "count"}
+	registerAll(t, engine, tool)
+	runSingleTask(t, engine, &Task{ID: "ungated", Input: "go"})
+	if consulted || tool.count() != 1 {
+		t.Errorf("ungated tool should run without approval (consulted=%v, runs=%d)", consulted, tool.count())
+	}
+}
+
+func TestTerminalApprover_answers(t *testing.T) {
+	var out bytes.Buffer
+	a := NewTerminalApprover(strings.NewReader("maybe\ny\ne {\"url\":\"x\"}\nn too risky\n"), &out)
+	req := ApprovalRequest{TaskID: "t", Tool: "fetch", Arguments: "{}", Capabilities: []llm.Capability{llm.CapNetwork}}
+
+	want := []ApprovalDecision{
+		{Verdict: ApprovalApproved},
+		{Verdict: ApprovalEdited, Arguments: `{"url":"x"}`},
+		{Verdict: ApprovalDenied, Reason: "too risky"},
+		{Verdict: ApprovalDenied, Reason: "approval input closed"},
+	}
+	for i, w := range want {
+		got, err := a.Approve(context.Background(), req)
+		if err != nil || got != w {
+			t.Errorf("answer %d: got %+v (err %v), want %+v", i, got, err, w)
+		}
+	}
+	if !strings.Contains(out.String(), "unrecognised answer") || !strings.Contains(out.String(), "capabilities: network") {
+		t.Errorf("unexpected prompt output:\n%s", out.String())
+	}
+}
+
+func TestTerminalApprover_lateAnswerDoesNotApproveNextPrompt(t *testing.T) {
+	a := NewTerminalApprover(strings.NewReader(""), io.Discard)
+	a.once.Do(func() {}) // the test feeds a.lines in place of the reader
+	req := ApprovalRequest{TaskID: "t", Tool: "fetch", Arguments: "{}"}
+
+	expired, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := a.Approve(expired, req); !errors.Is(err, context.Canceled) {
+		t.Fatalf("want unanswered prompt to expire, got %v", err)
+	}
+
+	late := a.tag("y") // typed after the first prompt expired
+	done := make(chan ApprovalDecision, 1)
+	go func() {
+		d, _ := a.Approve(context.Background(), req)
+		done <- d
+	}()
+	a.lines <- late
+	a.lines <- a.tag("n not this one")
+
+	if d := <-done; d.Verdict != ApprovalDenied || d.Reason != "not this one" {
+		t.Errorf("late answer leaked into the next prompt: %+v", d)
+	}
+}
@@ -0,0 +1,188 @@
+package core
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+const (
+	// defaultKeepRecent is how many trailing messages ContextWindow never alters.
+	defaultKeepRecent = 4
+	// compactedToolResultChars is how much of an old tool result survives compaction.
+	compactedToolResultChars = 256
+	// messageOverheadTokens approximates per-message framing in chat templates.
+	messageOverheadTokens = 4
+)
+
+// ContextWindow keeps the ReAct message history within a model's context limit.
+// When the estimated size exceeds the limit it first compacts old tool results
+// (oldest first), then drops the oldest complete turns. The leading system
+// prompt(s), the user's objective and the KeepRecent latest messages are never
+// altered. The engine's full history is untouched; only the view sent to the
+// model is reduced.
+type ContextWindow struct {
+	// MaxTokens is the default context limit.
+	MaxTokens int
+	// ModelLimits overrides MaxTokens for tasks whose TaskOptions.Model matches a key.
+	ModelLimits map[string]int
+	// ReserveTokens is headroom kept free for the model's completion.
+	ReserveTokens int
+	// KeepRecent is the number of trailing messages kept intact (default 4).
+	KeepRecent int
+	// Estimate returns the token cost of a message. Defaults to ~4 chars per token.
+	Estimate func(llm.Message) int
+	// Summarize optionally condenses an old tool result. When nil, results are
+	// truncated to their first compactedToolResultChars characters.
+	Summarize func(content string) string
+}
+
+// WithContextWindow enables context-window management for all tasks.
+func (e *Engine) WithContextWindow(w *ContextWindow) *Engine {
+	e.window = w
+	return e
+}
+
+// EstimateTokens is the default heuristic: roughly four characters per token
+// plus a fixed per-message overhead.
+//
+//nolint:gocritic // hugeParam: Message is used by value throughout Layer 0
+func EstimateTokens(m llm.Message) int {
+	chars := len(m.Content)
+	for _, tc := range m.ToolCalls {
+		chars += len(tc.Name) + len(tc.Arguments)
+	}
+	for _, tr := range m.ToolResults {
+		chars += len(tr.Content)
+	}
+	return chars/4 + messageOverheadTokens
+}
+
+func (w *ContextWindow) limitFor(model string) int {
+	limit := w.MaxTokens
+	if l, ok := w.ModelLimits[model]; ok {
+		limit = l
+	}
+	return limit - w.ReserveTokens
+}
+
+func (w *ContextWindow) estimate(messages []llm.Message) int {
+	est := w.Estimate
+	if est == nil {
+		est = EstimateTokens
+	}
+	total := 0
+	for i := range messages {
+		total += est(messages[i])
+	}
+	return total
+}
+
+// Fit returns a view of messages that fits the context limit for model. The
+// input slice is never modified. If the protected messages alone exceed the
+// limit, the most reduced view is returned and the model call may still fail.
+func (w *ContextWindow) Fit(messages []llm.Message, model string) []llm.Message {
+	if w == nil {
+		return messages
+	}
+	limit := w.limitFor(model)
+	if limit <= 0 || w.estimate(messages) <= limit {
+		return messages
+	}
+
+	out := make([]llm.Message, len(messages))
+	copy(out, messages)
+	head, tail := w.bounds(out)
+
+	// Pass 1: compact old tool results, oldest first.
+	for i := head; i < tail; i++ {
+		if out[i].Role != "tool" {
+			continue
+		}
+		out[i] = w.compact(out[i])
+		if w.estimate(out) <= limit {
+			return out
+		}
+	}
+
+	// Pass 2: drop the oldest complete turns between head and tail.
+	dropped := 0
+	base := w.estimate(out[:head])
+	for head+dropped < tail && base+w.estimate(out[head+dropped:]) > limit {
+		dropped += turnLength(out, head+dropped, tail)
+	}
+	if dropped == 0 {
+		return out
+	}
+
+	reduced := make([]llm.Message, 0, len(out)-dropped+1)
+	reduced = append(reduced, out[:head]...)
+	reduced = append(reduced, llm.Message{
+		Role:    "system",
+		Content: fmt.Sprintf("[%d earlier messages omitted to fit the context window]", dropped),
+	})
+	reduced = append(reduced, out[head+dropped:]...)
+	return reduced
+}
+
+// bounds returns the index of the first mutable message (after leading system
+// prompts and the first user message) and the index where the protected tail
+// begins. The tail never starts on a tool message, so an assistant turn and
+// its tool results are always kept or dropped together.
+func (w *ContextWindow) bounds(messages []llm.Message) (head, tail int) {
+	for head < len(messages) && messages[head].Role == "system" {
+		head++
+	}
+	if head < len(messages) && messages[head].Role == "user" {
+		head++
+	}
+
+	keep := w.KeepRecent
+	if keep <= 0 {
+		keep = defaultKeepRecent
+	}
+	tail = len(messages) - keep
+	for tail > head && tail < len(messages) && messages[tail].Role == "tool" {
+		tail--
+	}
+	if tail < head {
+		tail = head
+	}
+	return head, tail
+}
+
+// turnLength returns how many messages starting at i form one droppable unit:
+// an assistant message together with the tool results that answer it.
+func turnLength(messages []llm.Message, i, limit int) int {
+	n := 1
+	if messages[i].Role == "assistant" && len(messages[i].ToolCalls) > 0 {
+		for i+n < limit && messages[i+n].Role == "tool" {
+			n++
+		}
+	}
+	return n
+}
+
+// compact shrinks every tool result in m.
+//
+//nolint:gocritic // hugeParam: Message is used by value throughout Layer 0
+func (w *ContextWindow) compact(m llm.Message) llm.Message {
+	results := make([]llm.ToolResultMessage, len(m.ToolResults))
+	for i, r := range m.ToolResults {
+		if w.Summarize != nil {
+			r.Content = w.Summarize(r.Content)
+		} else if len(r.Content) > compactedToolResultChars {
+			cut := compactedToolResultChars
+			for cut > 0 && !utf8.RuneStart(r.Content[cut]) {
+				cut--
+			}
+			r.Content = fmt.Sprintf("%s…[truncated %d chars]", r.Content[:cut], len(r.Content)-cut)
+		}
+		results[i] = r
+	}
+	m.ToolResults = results
+	return m
+}
@@ -0,0 +1,128 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+// reactHistory builds system + user + n assistant/tool turns whose tool
+// results are each blobChars long.
+func reactHistory(n, blobChars int) []llm.Message {
+	msgs := []llm.Message{
+		{Role: "system", Content: "sys"},
+		{Role: "user", Content: "objective"},
+	}
+	for i := range n {
+		id := string(rune('a' + i))
+		msgs = append(msgs,
+			llm.Message{Role: "assistant", ToolCalls: []llm.ToolCall{{ID: id, Name: "dump", Arguments: "{}"}}},
+			llm.Message{Role: "tool", ToolResults: []llm.ToolResultMessage{{ToolCallID: id, Content: strings.Repeat("x", blobChars)}}},
+		)
+	}
+	return msgs
+}
+
+func TestContextWindow_noopWhenWithinLimit(t *testing.T) {
+	w := &ContextWindow{MaxTokens: 10_000}
+	msgs := reactHistory(2, 100)
+	if got := w.Fit(msgs, ""); len(got) != len(msgs) || &got[0] != &msgs[0] {
+		t.Error("messages within the limit should be returned as-is")
+	}
+	var nilWindow *ContextWindow
+	if got := nilWindow.Fit(msgs, ""); len(got) != len(msgs) {
+		t.Error("nil window must be a no-op")
+	}
+}
+
+func TestContextWindow_compactsOldToolResultsFirst(t *testing.T) {
+	msgs := reactHistory(4, 4000) // ~1000 tokens per tool result
+	w := &ContextWindow{MaxTokens: 2500, KeepRecent: 2}
+
+	got := w.Fit(msgs, "")
+	if len(got) != len(msgs) {
+		t.Fatalf("compaction alone should suffice, got %d messages", len(got))
+	}
+	if w.estimate(got) > 2500 {
+		t.Errorf("result still exceeds limit: %d", w.estimate(got))
+	}
+	if !strings.Contains(got[3].ToolResults[0].Content, "truncated") {
+		t.Error("oldest tool result should be compacted")
+	}
+	last := got[len(got)-1].ToolResults[0].Content
+	if len(last) != 4000 {
+		t.Error("latest tool result must be kept intact")
+	}
+	if len(msgs[3].ToolResults[0].Content) != 4000 {
+		t.Error("Fit must not mutate the caller's history")
+	}
+}
+
+func TestContextWindow_dropsOldTurnsWhenCompactionIsNotEnough(t *testing.T) {
+	msgs := reactHistory(6, 400)
+	// Each turn costs ~120 tokens even after compaction; force dropping.
+	w := &ContextWindow{MaxTokens: 300, KeepRecent: 2, Summarize: func(s string) string { return s[:200] }}
+
+	got := w.Fit(msgs, "")
+	if got[0].Role != "system" || got[0].Content != "sys" || got[1].Content != "objective" {
+		t.Fatalf("system prompt and objective must be preserved: %+v", got[:2])
+	}
+	if got[2].Role != "system" || !strings.Contains(got[2].Content, "omitted") {
+		t.Errorf("expected an omission note, got %+v", got[2])
+	}
+	if got[len(got)-2].Role != "assistant" || got[len(got)-1].Role != "tool" {
+		t.Error("latest assistant/tool pair must be kept")
+	}
+	for i := 3; i < len(got); i++ {
+		if got[i].Role == "tool" && got[i-1].Role != "assistant" {
+			t.Errorf("tool result at %d lost its assistant turn", i)
+		}
+	}
+}
+
+func TestContextWindow_modelSpecificLimit(t *testing.T) {
+	msgs := reactHistory(3, 4000)
+	w := &ContextWindow{MaxTokens: 100_000, ModelLimits: map[string]int{"tiny": 1500}, KeepRecent: 2}
+	if got := w.Fit(msgs, "big"); w.estimate(got) != w.estimate(msgs) {
+		t.Error("default limit should not trigger compaction")
+	}
+	if got := w.Fit(msgs, "tiny"); w.estimate(got) > 1500 {
+		t.Errorf("model limit not applied: %d", w.estimate(got))
+	}
+}
+
+func TestEngine_contextWindowAppliedToPrompt(t *testing.T) {
+	blob := strings.Repeat("row ", 2000)
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{
+		{ToolCalls: []llm.ToolCall{{ID: "c1", Name: "blob", Arguments: "{}"}}},
+		{ToolCalls: []llm.ToolCall{{ID: "c2", Name: "blob", Arguments: "{}"}}},
+		{ToolCalls: []llm.ToolCall{{ID: "c3", Name: "blob", Arguments: "{}"}}},
+		{Content: "done"},
+	}}
+	engine := NewEngine(adapter, 1, 1).WithContextWindow(&ContextWindow{MaxTokens: 4500, KeepRecent: 2})
+	registerAll(t, engine, &fixedTool{name: "blob", out: blob})
+
+	res := runSingleTask(t, engine, &Task{ID: "cw", Input: "dump"})
+	if res.Error != nil {
+		t.Fatalf("unexpected error: %v", res.Error)
+	}
+	w := engine.window
+	for i, req := range adapter.requests {
+		if est := w.estimate(req); est > 4500 {
+			t.Errorf("request %d exceeded the window: %d tokens", i, est)
+		}
+	}
+}
+
+// fixedTool returns a constant output.
+type fixedTool struct {
+	name string
+	out  string
+}
+
+func (f *fixedTool) Manifest() llm.ToolManifest { return llm.ToolManifest{Name: f.name} }
+func (f *fixedTool) Execute(_ context.Context, _ string) (string, error) {
+	return f.out, nil
+}
@@ -0,0 +1,141 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+// ErrDeadLetterNotFound is returned by Redrive for an unknown task ID.
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// defaultDeadLetterCapacity bounds a MemoryDeadLetterStore created with capacity 0.
+const defaultDeadLetterCapacity = 1024
+
+// DeadLetter is a task that failed with a transient error after exhausting
+// its retry policy.
+type DeadLetter struct {
+	Task     JournaledTask
+	Attempts int
+	Error    string
+	FailedAt time.Time
+}
+
+// DeadLetterStore keeps dead-lettered tasks for inspection and re-drive.
+// Implementations must be safe for concurrent use.
+type DeadLetterStore interface {
+	Add(dl DeadLetter) error
+	// List returns the stored dead letters, oldest first.
+	List() ([]DeadLetter, error)
+	// Take removes and returns the dead letter for taskID.
+	Take(taskID string) (DeadLetter, bool, error)
+}
+
+// MemoryDeadLetterStore is a bounded in-process DeadLetterStore. When full,
+// the oldest entry is dropped.
+type MemoryDeadLetterStore struct {
+	mu       sync.Mutex
+	capacity int
+	letters  []DeadLetter
+}
+
+// NewMemoryDeadLetterStore returns a store holding at most capacity entries.
+// Zero means defaultDeadLetterCapacity.
+func NewMemoryDeadLetterStore(capacity int) *MemoryDeadLetterStore {
+	if capacity <= 0 {
+		capacity = defaultDeadLetterCapacity
+	}
+	return &MemoryDeadLetterStore{capacity: capacity}
+}
+
+func (s *MemoryDeadLetterStore) Add(dl DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.letters) >= s.capacity {
+		s.letters = s.letters[1:]
+	}
+	s.letters = append(s.letters, dl)
+	return nil
+}
+
+func (s *MemoryDeadLetterStore) List() ([]DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]DeadLetter(nil), s.letters...), nil
+}
+
+func (s *MemoryDeadLetterStore) Take(taskID string) (DeadLetter, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, dl := range s.letters {
+		if dl.Task.ID == taskID {
+			s.letters = append(s.letters[:i], s.letters[i+1:]...)
+			return dl, true, nil
+		}
+	}
+	return DeadLetter{}, false, nil
+}
+
+// WithDeadLetters routes tasks that fail with a transient error, after any
+// retries, to store.
+func (e *Engine) WithDeadLetters(store DeadLetterStore) *Engine {
+	e.deadLetters = store
+	return e
+}
+
+// DeadLetters returns the engine's dead-letter store, or nil if none is attached.
+func (e *Engine) DeadLetters() DeadLetterStore {
+	return e.deadLetters
+}
+
+// Redrive resubmits a dead-lettered task under its original ID and options.
+// The entry is restored to the store if the submission fails.
+func (e *Engine) Redrive(ctx context.Context, taskID string) error {
+	if e.deadLetters == nil {
+		return fmt.Errorf("%w: %s", ErrDeadLetterNotFound, taskID)
+	}
+	dl, ok, err := e.deadLetters.Take(taskID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrDeadLetterNotFound, taskID)
+	}
+
+	t := &Task{ID: dl.Task.ID, System: dl.Task.System, Input: dl.Task.Input, CreatedAt: dl.Task.CreatedAt, Options: dl.Task.Options}
+	if err := e.SubmitContext(ctx, t); err != nil {
+		if addErr := e.deadLetters.Add(dl); addErr != nil {
+			return errors.Join(err, addErr)
+		}
+		return err
+	}
+	WithTask(ctx, taskID).Info("dead_letter_redriven", slog.Int("previous_attempts", dl.Attempts))
+	return nil
+}
+
+// deadLetter stores a task whose final error was transient.
+func (e *Engine) deadLetter(t *Task, attempts int, err error) {
+	if e.deadLetters == nil || !isTransient(err) {
+		return
+	}
+	dl := DeadLetter{
+		Task: JournaledTask{
+			ID: t.ID, System: t.System, Input: t.Input, CreatedAt: t.CreatedAt, Options: t.Options,
+		},
+		Attempts: attempts,
+		Error:    err.Error(),
+		FailedAt: time.Now(),
+	}
+	if addErr := e.deadLetters.Add(dl); addErr != nil {
+		WithTask(context.Background(), t.ID).Error("dead_letter_store_failed", slog.String("error", addErr.Error()))
+		return
+	}
+	e.logAudit(context.Background(), t.ID+"-dead-letter", "AUDIT_TASK_DEAD_LETTERED", "engine", map[string]interface{}{
+		"task_id": t.ID, "attempts": attempts, "error": err.Error(),
+	})
+}
@@ -0,0 +1,220 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+var (
+	ErrDelegationDepthExceeded = errors.New("delegation depth limit reached")
+	ErrInvalidDelegation       = errors.New("invalid delegation request")
+)
+
+const (
+	// DelegateToolName is the name under which the delegation tool is registered.
+	DelegateToolName = "delegate"
+	// defaultDelegationDepth bounds nested delegation when DelegationConfig.MaxDepth is unset.
+	defaultDelegationDepth = 2
+)
+
+// usageMeter accumulates token usage for one task and charges it to every
+// ancestor, so sub-agents draw on their parents' budgets.
+type usageMeter struct {
+	mu     sync.Mutex
+	parent *usageMeter
+	limit  TokenBudget
+	used   llm.TokenUsage
+}
+
+// charge records u against this meter and all ancestors, returning the first
+// budget violation encountered.
+func (m *usageMeter) charge(u llm.TokenUsage) error {
+	var first error
+	for cur := m; cur != nil; cur = cur.parent {
+		cur.mu.Lock()
+		addUsage(&cur.used, u)
+		err := cur.limit.check(cur.used)
+		cur.mu.Unlock()
+		if first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// total returns the usage of the task and all of its sub-agents so far.
+func (m *usageMeter) total() llm.TokenUsage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.used
+}
+
+// taskScope is the execution lineage of a running task, carried in its context
+// so that the delegate tool can spawn children bound to it.
+type taskScope struct {
+	taskID  string
+	depth   int
+	meter   *usageMeter
+	options *TaskOptions
+}
+
+type taskScopeKey struct{}
+
+// enterScope derives the scope of t from any parent scope in ctx. used seeds
+// the meter for tasks resumed from a checkpoint.
+func enterScope(ctx context.Context, t *Task, used llm.TokenUsage) (context.Context, *taskScope) {
+	s := &taskScope{taskID: t.ID, options: &t.Options, meter: &usageMeter{limit: t.Options.TokenBudget, used: used}}
+	if parent, ok := ctx.Value(taskScopeKey{}).(*taskScope); ok {
+		s.depth = parent.depth + 1
+		s.meter.parent = parent.meter
+	}
+	return context.WithValue(ctx, taskScopeKey{}, s), s
+}
+
+// DelegationConfig configures the built-in delegate tool.
+type DelegationConfig struct {
+	// MaxDepth bounds nesting: a root task is depth 0 and its sub-agents depth 1.
+	// Zero means defaultDelegationDepth.
+	MaxDepth int
+	// MaxIterations caps each sub-agent's ReAct loop. Zero means the engine default.
+	MaxIterations int
+}
+
+// EnableDelegation registers the built-in delegate tool, which lets an agent
+// hand a focused sub-goal to a fresh sub-agent. The child runs synchronously
+// through this engine with a clean context, an optionally narrower tool set,
+// the parent's remaining time and a token budget shared with the parent. Its
+// audit events and transcript records carry the parent task ID.
+func (e *Engine) EnableDelegation(cfg DelegationConfig) error {
+	if cfg.MaxDepth <= 0 {
+		cfg.MaxDepth = defaultDelegationDepth
+	}
+	return e.tools.Register(&delegateTool{engine: e, cfg: cfg})
+}
+
+// delegateTool is the Tool behind EnableDelegation.
+type delegateTool struct {
+	engine *Engine
+	cfg    DelegationConfig
+	seq    atomic.Uint64
+}
+
+type delegateArgs struct {
+	Goal   string   `json:"goal"`
+	System string   `json:"system,omitempty"`
+	Tools  []string `json:"tools,omitempty"`
+}
+
+func (d *delegateTool) Manifest() llm.ToolManifest {
+	return llm.ToolManifest{
+		Name: DelegateToolName,
+		Description: "Hand a focused sub-goal to a fresh sub-agent with a clean context. " +
+			"Optionally restrict the tools it may use. Returns the sub-agent's final answer.",
+		Parameters: json.RawMessage(`{
+			"type": "object",
+			"properties": {
+				"goal":   {"type": "string", "minLength": 1, "description": "Self-contained objective for the sub-agent"},
+				"system": {"type": "string", "description": "Optional system prompt for the sub-agent"},
+				"tools":  {"type": "array", "items": {"type": "string"}, "description": "Subset of tools the sub-agent may use"}
+			},
+			"required": ["goal"],
+			"additionalProperties": false
+		}`),
+	}
+}
+
+func (d *delegateTool) Execute(ctx context.Context, args string) (string, error) {
+	parent, ok := ctx.Value(taskScopeKey{}).(*taskScope)
+	if !ok {
+		return "", fmt.Errorf("%w: delegate must run inside a task", ErrInvalidDelegation)
+	}
+	if parent.depth+1 > d.cfg.MaxDepth {
+		return "", fmt.Errorf("%w: max depth %d", ErrDelegationDepthExceeded, d.cfg.MaxDepth)
+	}
+	var req delegateArgs
+	if err := json.Unmarshal([]byte(args), &req); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidDelegation, err)
+	}
+
+	child := &Task{
+		ID:        fmt.Sprintf("%s/sub-%d", parent.taskID, d.seq.Add(1)),
+		System:    req.System,
+		Input:     req.Goal,
+		CreatedAt: time.Now(),
+		Options: TaskOptions{
+			MaxIterations:    d.cfg.MaxIterations,
+			Model:            parent.options.Model,
+			Tools:            d.childTools(parent, req.Tools),
+			MaxParallelTools: parent.options.MaxParallelTools,
+		},
+		ctx:    ctx,
+		parent: parent.taskID,
+	}
+	return d.engine.runChild(child)
+}
+
+// childTools narrows the parent's tool set to the requested subset. The
+// delegate tool itself is withheld once children would reach the depth limit.
+func (d *delegateTool) childTools(parent *taskScope, requested []string) []string {
+	allowed := make([]string, 0)
+	for _, m := range d.engine.tools.Manifests() {
+		switch {
+		case !parent.options.toolAllowed(m.Name):
+		case requested != nil && !slices.Contains(requested, m.Name):
+		case m.Name == DelegateToolName && parent.depth+2 > d.cfg.MaxDepth:
+		default:
+			allowed = append(allowed, m.Name)
+		}
+	}
+	return allowed
+}
+
+// runChild executes a sub-agent task synchronously on the calling goroutine
+// and records its lineage for audit and transcript consumers.
+func (e *Engine) runChild(child *Task) (string, error) {
+	e.lineage.Store(child.ID, child.parent)
+	defer e.lineage.Delete(child.ID)
+
+	log := WithTask(child.context(), child.ID).With(slog.String("parent_task_id", child.parent))
+	log.Info("delegated_task_started")
+	e.record(&TranscriptRecord{Type: TranscriptTask, TaskID: child.ID, Task: &JournaledTask{
+		ID: child.ID, System: child.System, Input: child.Input, CreatedAt: child.CreatedAt, Options: child.Options,
+	}})
+
+	start := time.Now()
+	res := &Result{}
+	ctx, cancel := child.Options.bind(child.context())
+	defer cancel()
+	out, err := e.executeEphemeral(ctx, child, res)
+	e.record(&TranscriptRecord{
+		Type: TranscriptResult, TaskID: child.ID, Output: out, Error: errorString(err), ErrorKinds: errorKinds(err),
+		DurationMs: time.Since(start).Milliseconds(),
+	})
+	if err != nil {
+		log.Warn("delegated_task_failed", slog.String("error", err.Error()))
+		return "", fmt.Errorf("sub-agent %s failed: %w", child.ID, err)
+	}
+	log.Info("delegated_task_completed", slog.Int("total_tokens", res.Usage.TotalTokens))
+	return out, nil
+}
+
+// parentOf returns the parent task ID of a delegated task, if any.
+func (e *Engine) parentOf(taskID string) string {
+	if p, ok := e.lineage.Load(taskID); ok {
+		if s, ok := p.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+func toolNames(ms []llm.ToolManifest) []string {
+	names := make([]string, 0, len(ms))
+	for _, m := range ms {
+		names = append(names, m.Name)
+	}
+	return names
+}
+
+// delegationScript drives a parent that delegates once to a child which calls
+// "count" and answers. Every response reports 10 tokens.
+func delegationScript(delegateArgs string) *scriptedLLM {
+	usage := llm.TokenUsage{TotalTokens: 10}
+	return &scriptedLLM{responses: []llm.LLMResponse{
+		{ToolCalls: []llm.ToolCall{{ID: "d1", Name: DelegateToolName, Arguments: delegateArgs}}, TokenUsage: usage},
+		{ToolCalls: []llm.ToolCall{{ID: "c1", Name: "count"}}, TokenUsage: usage},
+		{Content: "sub answer", TokenUsage: usage},
+		{Content: "final", TokenUsage: usage},
+	}}
+}
+
+func TestDelegate_runsChildWithCleanContextAndLineage(t *testing.T) {
+	adapter := delegationScript(`{"goal":"count the things","tools":["count"]}`)
+	al := &MockAuditLogger{}
+	var transcript bytes.Buffer
+	engine := NewEngine(adapter, 1, 1).WithAuditLogger(al).WithTranscript(NewTranscriptWriter(&transcript))
+	tool := &countingTool{name: "count"}
+	registerAll(t, engine, tool, &countingTool{name: "other"})
+	if err := engine.EnableDelegation(DelegationConfig{}); err != nil {
+		t.Fatalf("EnableDelegation: %v", err)
+	}
+
+	res := runSingleTask(t, engine, &Task{ID: "root", Input: "do it"})
+	if res.Error != nil || res.Output != "final" {
+		t.Fatalf("unexpected result %q (err %v)", res.Output, res.Error)
+	}
+	if tool.count() != 1 {
+		t.Errorf("child should have run count once, got %d", tool.count())
+	}
+
+	child := adapter.requests[1]
+	if len(child) != 2 || child[1].Content != "count the things" {
+		t.Errorf("child should start from a clean context, got %+v", child)
+	}
+	if got := toolNames(adapter.tools[1]); !slices.Equal(got, []string{"count"}) {
+		t.Errorf("child tools should be restricted to count, got %v", got)
+	}
+	if got := adapter.requests[3][3].ToolResults[0].Content; got != "sub answer" {
+		t.Errorf("parent should receive the child's answer, got %q", got)
+	}
+	if res.Usage.TotalTokens != 40 {
+		t.Errorf("parent usage should include the child's, got %d", res.Usage.TotalTokens)
+	}
+
+	linked := false
+	for _, ev := range al.Events {
+		if ev.Metadata["parent_task_id"] == "root" {
+			linked = true
+		}
+	}
+	if !linked {
+		t.Error("child audit events should carry parent_task_id")
+	}
+	records, err := ReadTranscript(&transcript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	childRecords := 0
+	for _, rec := range records {
+		if strings.HasPrefix(rec.TaskID, "root/sub-") {
+			childRecords++
+			if rec.ParentID != "root" {
+				t.Errorf("child record without parent link: %+v", rec)
+			}
+		}
+	}
+	if childRecords == 0 {
+		t.Error("expected transcript records for the child")
+	}
+}
+
+func TestDelegate_depthLimitWithholdsTool(t *testing.T) {
+	usage := llm.TokenUsage{TotalTokens: 1}
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{
+		{ToolCalls: []llm.ToolCall{{ID: "d1", Name: DelegateToolName, Arguments: `{"goal":"nested"}`}}, TokenUsage: usage},
+		{ToolCalls: []llm.ToolCall{{ID: "d2", Name: DelegateToolName, Arguments: `{"goal":"deeper"}`}}, TokenUsage: usage},
+		{Content: "child done", TokenUsage: usage},
+		{Content: "root done", TokenUsage: usage},
+	}}
+	engine := NewEngine(adapter, 1, 1)
+	registerAll(t, engine, &countingTool{name: "count"})
+	if err := engine.EnableDelegation(DelegationConfig{MaxDepth: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	res := runSingleTask(t, engine, &Task{ID: "root", Input: "go"})
+	if res.Error != nil || res.Output != "root done" {
+		t.Fatalf("unexpected result %q (err %v)", res.Output, res.Error)
+	}
+	if got := toolNames(adapter.tools[1]); slices.Contains(got, DelegateToolName) {
+		t.Errorf("delegate must be withheld at the depth limit, child saw %v", got)
+	}
+	nested := adapter.requests[2]
+	if msg := nested[len(nested)-1].ToolResults[0]; !msg.IsError || !strings.Contains(msg.Content, "not permitted") {
+		t.Errorf("nested delegation should be refused, got %+v", msg)
+	}
+}
+
+func TestDelegate_childSharesParentTokenBudget(t *testing.T) {
+	adapter := delegationScript(`{"goal":"count"}`)
+	engine := NewEngine(adapter, 1, 1)
+	registerAll(t, engine, &countingTool{name: "count"})
+	if err := engine.EnableDelegation(DelegationConfig{}); err != nil {
+		t.Fatal(err)
+	}
+
+	res := runSingleTask(t, engine, &Task{ID: "root", Input: "go", Options: TaskOptions{
+		TokenBudget: TokenBudget{MaxTotalTokens: 25},
+	}})
+	if !errors.Is(res.Error, ErrTokenBudgetExceeded) {
+		t.Fatalf("want the shared budget to stop the task, got %v", res.Error)
+	}
+	feedback := adapter.requests[len(adapter.requests)-1]
+	if msg := feedback[len(feedback)-1].ToolResults[0]; !strings.Contains(msg.Content, "token budget exceeded") {
+		t.Errorf("child should fail on the parent's budget, got %+v", msg)
+	}
+}
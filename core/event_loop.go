@@ -2,10 +2,10 @@ package core
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
-	"strings"
 	"sync"
 	"time"
 
@@ -17,8 +17,10 @@ import (
 // Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
 
 var (
-	ErrQueueFull  = errors.New("worker queue is full")
-	ErrTaskFailed = errors.New("task execution failed")
+	ErrQueueFull             = errors.New("worker queue is full")
+	ErrTaskFailed            = errors.New("task execution failed")
+	ErrMaxIterationsExceeded = errors.New("ErrMaxIterationsExceeded")
+	ErrToolNotPermitted      = errors.New("tool not permitted for this task")
 )
 
 // Task represents a single unit of work in AetherCore.
@@ -28,14 +30,26 @@ type Task struct {
 	System    string
 	Input     string
 	CreatedAt time.Time
+	Options   TaskOptions
+
+	events chan TaskEvent  // non-nil when submitted via SubmitStream
+	ctx    context.Context // submission context; see SubmitContext
+	handle *taskHandle     // control block for Cancel and Status
+	resume *resumeState    // checkpoint restored by Engine.Recover
+	parent string          // parent task ID for sub-agents started by the delegate tool
 }
 
 // Result encapsulates the outcome of a Task.
 type Result struct {
-	TaskID   string
-	Output   string
-	Duration time.Duration
-	Error    error
+	TaskID      string
+	Output      string
+	Duration    time.Duration
+	Error       error
+	Usage       llm.TokenUsage      // cumulative across all LLM calls of the task
+	JSON        json.RawMessage     // validated final answer when TaskOptions.OutputSchema is set
+	Steps       []StepStatus        // per-step outcome for plan-based strategies
+	Reflections []ReflectionVerdict // critic verdicts in review order; see WithReflection
+	Attempts    int                 // executions under TaskOptions.Retry, including the first
 }
 
 // Engine coordinates the worker pool and ephemeral execution lifecycle.
@@ -43,9 +57,9 @@ type Engine struct {
 	adapter       llm.LLMAdapter
 	tools         *ToolRegistry
 	sandboxClient *SandboxClient // optional: Layer 2 Rust Sandbox for unknown tools
-	taskQueue     chan *Task
+	queue         *taskQueue
 	resultQueue   chan *Result
-	workerCount   int
+	pool          *workerPool
 	wg            sync.WaitGroup
 	quit          chan struct{}
 	stopOnce      sync.Once
@@ -53,17 +67,35 @@ type Engine struct {
 	resultPool    sync.Pool
 	guard         security.PromptGuard
 	audit         audit.Logger
+	tracker       *taskTracker
+	window        *ContextWindow     // optional context-window manager
+	journal       Journal            // optional write-ahead task journal
+	transcript    TranscriptRecorder // optional per-task transcript capture
+	replay        *Replay            // non-nil in replay mode; see WithReplay
+	approval      *approvalGate      // optional human-in-the-loop gate; see WithApprover
+	lineage       sync.Map           // delegated task ID -> parent task ID
+	strategies    map[string]AgentStrategy
+	reflection    *ReflectionConfig // optional critic pass; see WithReflection
+	toolCache     *ToolCache        // results of tools with a CacheTTLMs; see WithToolCache
+	deadLetters   DeadLetterStore   // tasks that exhausted their retries; see WithDeadLetters
+
+	llmInterceptors  []LLMInterceptor
+	toolInterceptors []ToolInterceptor
 }
 
-// NewEngine initializes the core event loop with bounded goroutines.
+// NewEngine initializes the core event loop with bounded goroutines. Pass an
+// *llm.Orchestrator as adapter to route each model turn across providers.
 func NewEngine(adapter llm.LLMAdapter, workerCount, queueSize int) *Engine {
 	e := &Engine{
 		adapter:     adapter,
 		tools:       NewToolRegistry(nil),
-		taskQueue:   make(chan *Task, queueSize),
+		queue:       newTaskQueue(queueSize),
 		resultQueue: make(chan *Result, queueSize),
-		workerCount: workerCount,
+		pool:        newWorkerPool(workerCount),
 		quit:        make(chan struct{}),
+		tracker:     newTaskTracker(),
+		strategies:  builtinStrategies(),
+		toolCache:   NewToolCache(ToolCacheConfig{}),
 		guard: security.NewOrchestratorGuard(
 			security.NewRegexScanner(),
 			security.NewSemanticAnalyzer(),
@@ -107,34 +139,18 @@ func (e *Engine) WithAuditLogger(l audit.Logger) *Engine {
 
 // Start boots the worker pool. Sub-50ms target for Pico Mode.
 func (e *Engine) Start() {
-	if e.audit != nil {
-		_ = e.audit.LogEvent(context.Background(), &audit.Event{
-			ID:        "sys-boot",
-			Timestamp: time.Now(),
-			Type:      "AUDIT_ENGINE_BOOT",
-			Actor:     "system",
-			Metadata:  map[string]interface{}{"worker_count": e.workerCount},
-		})
-	}
-	for i := range e.workerCount {
-		e.wg.Add(1)
-		go e.worker(i)
-	}
+	e.logAudit(context.Background(), "sys-boot", "AUDIT_ENGINE_BOOT", "system",
+		map[string]interface{}{"worker_count": e.PoolStats().Workers})
+	e.startWorkers()
 }
 
-// Stop gracefully shuts down the worker pool.
+// Stop stops accepting work and shuts the worker pool down without draining
+// the queue; running tasks finish first. Queued tasks are not run: their
+// results carry ErrTaskCancelled and, with a journal attached, they remain
+// pending for Recover. See Shutdown for a bounded drain.
 func (e *Engine) Stop() {
-	e.stopOnce.Do(func() {
-		// Signal all workers to terminate their loops
-		close(e.quit)
-
-		// Strictly block until every single ephemeral worker has returned
-		e.wg.Wait()
-
-		// Only after all workers are dead is it safe to close the queues
-		close(e.taskQueue)
-		close(e.resultQueue)
-	})
+	e.queue.close()
+	e.halt()
 }
 
 // GetTask retrieves a zero-allocated Task from the sync pool.
@@ -155,14 +171,10 @@ func (e *Engine) GetResult() *Result {
 	return r
 }
 
-// Submit enqueues a task. Returns ErrQueueFull if the bounded queue is saturated.
+// Submit enqueues a task. Returns ErrQueueFull if the bounded queue is saturated
+// and ErrTenantQuotaExceeded if the task's tenant is at its quota.
 func (e *Engine) Submit(t *Task) error {
-	select {
-	case e.taskQueue <- t:
-		return nil
-	default:
-		return ErrQueueFull
-	}
+	return e.SubmitContext(context.Background(), t)
 }
 
 // Results provides a read-only channel to consume task outcomes.
@@ -179,6 +191,11 @@ func (e *Engine) RecycleResult(r *Result) {
 	r.Output = ""
 	r.Error = nil
 	r.Duration = 0
+	r.Usage = llm.TokenUsage{}
+	r.JSON = nil
+	r.Steps = nil
+	r.Reflections = nil
+	r.Attempts = 0
 	e.resultPool.Put(r)
 }
 
@@ -186,214 +203,327 @@ func (e *Engine) RecycleResult(r *Result) {
 func (e *Engine) worker(id int) {
 	defer e.wg.Done()
 	for {
+		exit, shrink := e.pool.retire()
+		if exit {
+			return
+		}
 		select {
 		case <-e.quit:
 			return
-		case t := <-e.taskQueue:
+		case <-shrink:
+		case <-e.queue.ready:
+			e.pool.begin()
 			start := time.Now()
+			e.process(id, e.queue.pop())
+			e.pool.end(time.Since(start))
+		}
+	}
+}
 
-			taskLog := WithTask(context.Background(), t.ID).With(slog.Int("worker_id", id))
-			taskLog.Info("ephemeral_task_started")
+// process executes one dequeued task end-to-end, publishes its Result and
+// recycles the Task.
+func (e *Engine) process(workerID int, t *Task) {
+	start := time.Now()
 
-			out, err := e.executeEphemeral(t)
-			duration := time.Since(start)
+	taskLog := WithTask(context.Background(), t.ID).With(slog.Int("worker_id", workerID))
+	taskLog.Info("ephemeral_task_started")
 
-			if err != nil {
-				taskLog.Error("ephemeral_task_failed", slog.String("error", err.Error()), slog.Duration("duration_ms", duration))
-			} else {
-				taskLog.Info("ephemeral_task_completed", slog.Duration("duration_ms", duration))
-			}
+	e.record(&TranscriptRecord{Type: TranscriptTask, TaskID: t.ID, Task: &JournaledTask{
+		ID: t.ID, System: t.System, Input: t.Input, CreatedAt: t.CreatedAt, Options: t.Options,
+	}})
 
-			res := e.GetResult()
-			res.TaskID = t.ID
-			res.Output = out
-			res.Duration = duration
-			res.Error = err
+	res := e.GetResult()
+	var out string
+	err := t.context().Err() // cancelled while still queued
+	if err != nil && errors.Is(context.Cause(t.context()), errAbandoned) {
+		err = fmt.Errorf("%w: %w", err, errAbandoned)
+	}
+	if err == nil {
+		out, err = e.executeWithRetry(t, res)
+	}
+	err = e.finish(t, err)
+	e.deadLetter(t, res.Attempts, err)
+	duration := time.Since(start)
 
-			e.resultQueue <- res
+	// Tasks abandoned by Shutdown stay pending in the journal for Recover.
+	if !errors.Is(context.Cause(t.context()), errAbandoned) {
+		completed := &JournalEntry{Type: JournalCompleted, TaskID: t.ID}
+		if err != nil {
+			completed.Error = err.Error()
+		}
+		e.journalAppend(completed)
+	}
+	e.record(&TranscriptRecord{
+		Type: TranscriptResult, TaskID: t.ID, Output: out, Error: errorString(err), ErrorKinds: errorKinds(err),
+		DurationMs: duration.Milliseconds(),
+	})
 
-			// Recycle the pointer back into the pool. Zero allocations.
-			t.ID = ""
-			t.System = ""
-			t.Input = ""
-			e.taskPool.Put(t)
+	if err != nil {
+		taskLog.Error("ephemeral_task_failed", slog.String("error", err.Error()), slog.Duration("duration_ms", duration))
+	} else {
+		taskLog.Info("ephemeral_task_completed", slog.Duration("duration_ms", duration))
+	}
+
+	t.closeStream(e.quit, out, err)
+
+	res.TaskID = t.ID
+	res.Output = out
+	res.Duration = duration
+	res.Error = err
+
+	select {
+	case e.resultQueue <- res:
+	default:
+		select {
+		case e.resultQueue <- res:
+		case <-e.quit: // stopping and nobody is reading results
+			e.RecycleResult(res)
 		}
 	}
+	e.queue.done(t.Options.Tenant)
+
+	// Recycle the pointer back into the pool. Zero allocations.
+	t.ID = ""
+	t.System = ""
+	t.Input = ""
+	t.Options = TaskOptions{}
+	t.ctx = nil
+	t.handle = nil
+	t.resume = nil
+	e.taskPool.Put(t)
 }
 
+// maxAgentIterations is the default ReAct iteration cap (see TaskOptions.MaxIterations).
 const maxAgentIterations = 10
 
-// executeEphemeral is the core orchestration loop for a single task.
-// No state leaks outside this function.
-func (e *Engine) executeEphemeral(t *Task) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
-
-	messages := make([]llm.Message, 0, 2)
-	messages = append(messages,
-		llm.Message{Role: "system", Content: "You are AetherCore Kernel. Execute the objective using tools."},
-		llm.Message{Role: "user", Content: t.Input},
-	)
+// executeEphemeral is the core orchestration entry point for a single task.
+// No state leaks outside this function. The task's strategy (ReAct unless
+// TaskOptions.Strategy selects another) runs under ctx, the task's submission
+// context bound by TaskOptions.bind, so caller cancellation reaches the LLM
+// and tools. Cumulative token usage is recorded on res as the run progresses.
+func (e *Engine) executeEphemeral(ctx context.Context, t *Task, res *Result) (string, error) {
+	strategy, err := e.strategyFor(&t.Options)
+	if err != nil {
+		return "", err
+	}
+	structured, err := newStructuredOutput(&t.Options)
+	if err != nil {
+		return "", err
+	}
 
-	guardRes := e.guard.Scan(ctx, t.Input, security.GuardConfig{})
-	if !guardRes.IsSafe {
-		WithTask(ctx, t.ID).Warn("security_violation_user_input",
-			slog.String("rule", guardRes.Violations[0].Category),
-			slog.String("description", guardRes.Violations[0].Description),
-		)
-		return "", fmt.Errorf("security_violation: %s", guardRes.Violations[0].Description)
+	if t.resume != nil {
+		res.Usage = t.resume.usage
 	}
+	ctx, scope := enterScope(ctx, t, res.Usage)
+	defer func() { res.Usage = scope.meter.total() }()
+
+	run := &AgentRun{
+		Task:       t,
+		Tools:      t.Options.filterManifests(e.tools.Manifests()),
+		engine:     e,
+		res:        res,
+		scope:      scope,
+		structured: structured,
+	}
+	return strategy.Run(ctx, run)
+}
 
-	for iteration := range maxAgentIterations {
-		if e.audit != nil {
-			_ = e.audit.LogEvent(ctx, &audit.Event{
-				ID:        t.ID + "-req",
-				Timestamp: time.Now(),
-				Type:      "AUDIT_LLM_REQUEST",
-				Actor:     "engine",
-				Metadata:  map[string]interface{}{"task_id": t.ID, "messages_count": len(messages)},
-			})
-		}
+// reactStrategy is the default ReAct loop: the model alternates between tool
+// calls and observations until it produces a final answer.
+type reactStrategy struct{}
 
-		res, err := e.adapter.GenerateWithTools(ctx, messages, e.tools.Manifests())
+func (reactStrategy) Name() string { return StrategyReAct }
+
+func (reactStrategy) Run(ctx context.Context, run *AgentRun) (string, error) {
+	t := run.Task
+	messages, start := prepareMessages(t)
+
+	for iteration := start; iteration < t.Options.maxIterations(); iteration++ {
+		if err := ctx.Err(); err != nil {
+			return "", fmt.Errorf("iter_%d: %w", iteration, err)
+		}
+		resp, err := run.Generate(run.OutputFormat(ctx, len(run.Tools) > 0), iteration, messages, run.Tools)
 		if err != nil {
 			return "", fmt.Errorf("llm_iter_%d: %w", iteration, err)
 		}
 
 		// LLM decided it's done — no more tool calls
-		if len(res.ToolCalls) == 0 {
-			return res.Content, nil
+		if len(resp.ToolCalls) == 0 {
+			retry, err := run.Finish(ctx, messages, resp.Content)
+			if err != nil {
+				return "", err
+			}
+			if retry == nil {
+				return resp.Content, nil
+			}
+			messages = append(messages, llm.Message{Role: "assistant", Content: resp.Content}, *retry)
+			continue
 		}
 
 		// Append assistant turn to history
 		messages = append(messages, llm.Message{
 			Role:      "assistant",
-			Content:   res.Content,
-			ToolCalls: res.ToolCalls,
+			Content:   resp.Content,
+			ToolCalls: resp.ToolCalls,
 		})
 
-		// Execute tools, feed results back
-		var results []llm.ToolResultMessage
-		for _, call := range res.ToolCalls {
-			result, execErr := e.dispatchTool(ctx, t.ID, call)
-
-			var contentStr string
-			if execErr != nil {
-				if strings.Contains(execErr.Error(), "security_violation") {
-					return "", execErr
-				}
-				contentStr = execErr.Error()
-			} else {
-				contentStr = result
-			}
-
-			results = append(results, llm.ToolResultMessage{
-				ToolCallID: call.ID,
-				Content:    contentStr,
-				IsError:    execErr != nil,
-			})
+		// Execute tools (concurrently where permitted), feed results back in order
+		results, err := run.RunTools(ctx, iteration, resp.ToolCalls)
+		if err != nil {
+			return "", err
 		}
 
 		messages = append(messages, llm.Message{
 			Role:        "tool",
 			ToolResults: results,
 		})
+		run.Checkpoint(iteration, messages)
 	}
 
-	return "", errors.New("ErrMaxIterationsExceeded")
+	return "", ErrMaxIterationsExceeded
 }
 
-// dispatchTool dynamically resolves execution to Layer 0 (internal) or Layer 2 (sandbox).
-func (e *Engine) dispatchTool(ctx context.Context, taskID string, call llm.ToolCall) (string, error) {
-	tool, err := e.tools.Get(call.Name)
-	if err == nil {
-		toolLog := WithComponent("tool_executor").With(slog.String("tool_name", call.Name))
-		toolLog.Debug("tool_execution_started", slog.String("arguments", call.Arguments))
-		if e.audit != nil {
-			_ = e.audit.LogEvent(ctx, &audit.Event{
-				ID:        taskID + "-tool-exec",
-				Timestamp: time.Now(),
-				Type:      "AUDIT_TOOL_EXECUTE",
-				Actor:     "engine",
-				Metadata:  map[string]interface{}{"task_id": taskID, "tool": call.Name},
-			})
-		}
-		toolStart := time.Now()
+// prepareMessages builds the initial history and first iteration index.
+// Tasks recovered from a journal checkpoint continue from their saved history.
+func prepareMessages(t *Task) ([]llm.Message, int) {
+	if t.resume != nil {
+		return t.resume.messages, t.resume.iteration + 1
+	}
 
-		res, execErr := tool.Execute(ctx, call.Arguments)
-		toolDuration := time.Since(toolStart)
+	messages := make([]llm.Message, 0, 2)
+	messages = append(messages,
+		llm.Message{Role: "system", Content: t.systemPrompt()},
+		llm.Message{Role: "user", Content: t.Input},
+	)
+	return messages, 0
+}
 
-		if execErr == nil {
-			if scanErr := e.verifyToolOutput(ctx, taskID, call.Name, res); scanErr != nil {
-				return "", scanErr
-			}
+// generate performs a single LLM turn through the interceptor chain. Streamed
+// tasks receive content deltas as they arrive; all other tasks use the plain
+// request/response path.
+func (e *Engine) generate(ctx context.Context, t *Task, iteration int, messages []llm.Message, tools []llm.ToolManifest) (llm.LLMResponse, error) {
+	call := &LLMCall{TaskID: t.ID, Iteration: iteration, Messages: messages, Tools: tools}
+	return e.llmChain(func(ctx context.Context, call *LLMCall) (llm.LLMResponse, error) {
+		if t.events == nil {
+			return e.adapter.GenerateWithTools(ctx, call.Messages, call.Tools)
 		}
+		return llm.GenerateStream(ctx, e.adapter, call.Messages, call.Tools, func(c llm.StreamChunk) {
+			t.emit(ctx.Done(), TaskEvent{Type: EventContentDelta, Iteration: call.Iteration, Content: c.Content})
+		})
+	})(ctx, call)
+}
 
-		if execErr != nil {
-			toolLog.Error("tool_execution_failed", slog.String("error", execErr.Error()), slog.Duration("duration_ms", toolDuration))
-			return "", execErr
-		}
-		toolLog.Info("tool_execution_completed", slog.Duration("duration_ms", toolDuration))
-		return res, nil
+// dispatchTool runs a tool call through the interceptor chain.
+func (e *Engine) dispatchTool(ctx context.Context, taskID string, iteration int, call llm.ToolCall) (string, error) {
+	inv := &ToolInvocation{TaskID: taskID, Iteration: iteration, Call: call}
+	return e.toolChain(func(ctx context.Context, inv *ToolInvocation) (string, error) {
+		return e.resolveCached(ctx, inv.Call)
+	})(ctx, inv)
+}
+
+// resolveTool dynamically resolves execution to Layer 0 (internal) or Layer 2 (sandbox).
+func (e *Engine) resolveTool(ctx context.Context, call llm.ToolCall) (string, error) {
+	tool, err := e.tools.Get(call.Name)
+	if err == nil {
+		return e.dispatchLocal(ctx, tool, call)
 	}
 
 	// Unknown tool — forward to Rust sandbox
 	if e.sandboxClient != nil {
-		sbLog := WithComponent("sandbox_dispatcher").With(slog.String("tool_name", call.Name))
-		sbLog.Info("unknown_tool_dispatched_to_sandbox")
-
-		sbStart := time.Now()
-		output, sbErr := e.sandboxClient.ExecuteTool(ctx, call.Name, call.Arguments, "")
-		sbDuration := time.Since(sbStart)
-
-		if e.audit != nil {
-			_ = e.audit.LogEvent(ctx, &audit.Event{
-				ID:        taskID + "-tool-res",
-				Timestamp: time.Now(),
-				Type:      "AUDIT_TOOL_RESULT",
-				Actor:     "engine",
-				Metadata:  map[string]interface{}{"task_id": taskID, "tool_name": call.Name, "duration_ms": sbDuration.Milliseconds()},
-			})
-		}
+		return e.dispatchSandbox(ctx, call)
+	}
 
-		if sbErr != nil {
-			sbLog.Error("sandbox_execution_failed",
-				slog.String("error", sbErr.Error()),
-				slog.Duration("duration_ms", sbDuration),
-			)
-			return "", sbErr
-		}
+	return "", fmt.Errorf("tool_not_found: %s", call.Name)
+}
 
-		if scanErr := e.verifyToolOutput(ctx, taskID, call.Name, output); scanErr != nil {
-			return "", scanErr
-		}
+// dispatchLocal executes a registered Layer 0 tool inside its manifest envelope:
+// the capability policy is re-checked and MaxRuntimeMs bounds the execution.
+func (e *Engine) dispatchLocal(ctx context.Context, tool Tool, call llm.ToolCall) (string, error) {
+	toolLog := WithComponent("tool_executor").With(slog.String("tool_name", call.Name))
+	manifest := tool.Manifest()
+	if capErr := e.tools.CheckCapabilities(&manifest); capErr != nil {
+		toolLog.Warn("tool_capability_denied", slog.String("error", capErr.Error()))
+		return "", capErr
+	}
 
-		sbLog.Info("sandbox_execution_completed", slog.Duration("duration_ms", sbDuration))
-		return output, nil
+	if argErr := e.tools.ValidateArguments(call.Name, call.Arguments); argErr != nil {
+		toolLog.Warn("tool_arguments_rejected", slog.String("error", argErr.Error()))
+		return "", argErr
 	}
 
-	return "", fmt.Errorf("tool_not_found: %s", call.Name)
+	toolLog.Debug("tool_execution_started", slog.String("arguments", call.Arguments))
+	toolStart := time.Now()
+
+	res, execErr := executeBounded(ctx, tool, &manifest, call.Arguments)
+	toolDuration := time.Since(toolStart)
+
+	if execErr != nil {
+		toolLog.Error("tool_execution_failed", slog.String("error", execErr.Error()), slog.Duration("duration_ms", toolDuration))
+		return "", execErr
+	}
+	if outErr := e.tools.ValidateOutput(call.Name, res); outErr != nil {
+		toolLog.Warn("tool_output_rejected", slog.String("error", outErr.Error()))
+		return "", outErr
+	}
+	toolLog.Info("tool_execution_completed", slog.Duration("duration_ms", toolDuration))
+	return res, nil
+}
+
+// dispatchSandbox forwards a tool call to the Layer 2 Rust sandbox.
+func (e *Engine) dispatchSandbox(ctx context.Context, call llm.ToolCall) (string, error) {
+	sbLog := WithComponent("sandbox_dispatcher").With(slog.String("tool_name", call.Name))
+	sbLog.Info("unknown_tool_dispatched_to_sandbox")
+
+	sbStart := time.Now()
+	output, sbErr := e.sandboxClient.ExecuteTool(ctx, call.Name, call.Arguments, "")
+	sbDuration := time.Since(sbStart)
+
+	if sbErr != nil {
+		sbLog.Error("sandbox_execution_failed",
+			slog.String("error", sbErr.Error()),
+			slog.Duration("duration_ms", sbDuration),
+		)
+		return "", sbErr
+	}
+
+	sbLog.Info("sandbox_execution_completed", slog.Duration("duration_ms", sbDuration))
+	return output, nil
+}
+
+// logAudit appends an event to the audit chain when an audit logger is attached.
+// Events of delegated tasks are linked to their parent via parent_task_id.
+func (e *Engine) logAudit(ctx context.Context, id, eventType, actor string, metadata map[string]interface{}) {
+	if e.audit == nil {
+		return
+	}
+	if taskID, ok := metadata["task_id"].(string); ok {
+		if parent := e.parentOf(taskID); parent != "" {
+			metadata["parent_task_id"] = parent
+		}
+	}
+	_ = e.audit.LogEvent(ctx, &audit.Event{
+		ID:        id,
+		Timestamp: time.Now(),
+		Type:      eventType,
+		Actor:     actor,
+		Metadata:  metadata,
+	})
 }
 
+// verifyToolOutput screens tool output with the prompt guard; see guardToolCall.
 func (e *Engine) verifyToolOutput(ctx context.Context, taskID, toolName, output string) error {
 	if e.guard == nil {
 		return nil
 	}
 
 	res := e.guard.Scan(ctx, output, security.GuardConfig{})
+	e.recordGuard(taskID, GuardStageToolOutput, toolName, &res)
 	if res.IsSafe {
 		return nil
 	}
 
-	if e.audit != nil {
-		_ = e.audit.LogEvent(ctx, &audit.Event{
-			ID:        taskID + "-violation",
-			Timestamp: time.Now(),
-			Type:      "AUDIT_SECURITY_VIOLATION",
-			Actor:     "prompt-guard",
-			Metadata:  map[string]interface{}{"task_id": taskID, "reason": res.Violations[0].Description},
-		})
-	}
+	e.logAudit(ctx, taskID+"-violation", "AUDIT_SECURITY_VIOLATION", "prompt-guard",
+		map[string]interface{}{"task_id": taskID, "reason": res.Violations[0].Description})
 
 	WithComponent("tool_executor").Warn("tool_output_security_violation_detected",
 		slog.String("tool", toolName),
@@ -229,3 +229,29 @@ func TestReAct_maxIterationsExceeded(t *testing.T) {
 func newTestOllamaAdapterFromPkg(_, baseURL string) llm.LLMAdapter {
 	return llm.NewOllamaAdapterWithURL("test", baseURL)
 }
+
+// TestReAct_throughOrchestrator runs a tool loop on an Orchestrator, which
+// routes each turn to the healthiest real adapter.
+func TestReAct_throughOrchestrator(t *testing.T) {
+	srv := mockOllamaServer(t, []any{
+		ollamaResp{Model: "test", Message: ollamaMsg{Role: "assistant", ToolCalls: []ollamaTC{{
+			Function: ollamaTCFunc{Name: "echo", Arguments: json.RawMessage(`{"x":1}`)},
+		}}}, Done: true},
+		ollamaResp{Model: "test", Message: ollamaMsg{Role: "assistant", Content: "Tool said: done"}, Done: true},
+	})
+	defer srv.Close()
+
+	primary := llm.NewProvider(llm.NewOllamaAdapterWithURL("primary", "http://127.0.0.1:1"), 1, llm.ModelMetadata{})
+	primary.SetStatus(llm.StatusOffline)
+	secondary := llm.NewProvider(newTestOllamaAdapterFromPkg("test", srv.URL), 2, llm.ModelMetadata{})
+	router := llm.NewFallbackRouter([]llm.Provider{primary, llm.NewRetryingProvider(secondary, 2)})
+
+	engine := NewEngine(llm.NewOrchestrator(router), 1, 4)
+	if err := engine.RegisterTool(&testEchoTool{name: "echo"}); err != nil {
+		t.Fatalf("RegisterTool: %v", err)
+	}
+	res := runSingleTask(t, engine, &Task{ID: "routed", Input: "Run echo tool.", CreatedAt: time.Now()})
+	if res.Error != nil || res.Output != "Tool said: done" {
+		t.Fatalf("unexpected result %q (err %v)", res.Output, res.Error)
+	}
+}
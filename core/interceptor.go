@@ -0,0 +1,178 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/fzihak/aethercore/core/llm"
+	"github.com/fzihak/aethercore/core/security"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+// LLMCall is one model turn travelling through the LLM interceptor chain.
+// Interceptors may rewrite Messages and Tools before calling next.
+type LLMCall struct {
+	TaskID    string
+	Iteration int
+	Messages  []llm.Message
+	Tools     []llm.ToolManifest
+}
+
+// LLMHandler performs (the remainder of) an LLM call.
+type LLMHandler func(ctx context.Context, call *LLMCall) (llm.LLMResponse, error)
+
+// LLMInterceptor wraps an LLM call. It may inspect or modify the call, invoke
+// next and post-process its response, or short-circuit by returning without
+// calling next.
+type LLMInterceptor func(ctx context.Context, call *LLMCall, next LLMHandler) (llm.LLMResponse, error)
+
+// ToolInvocation is one tool call travelling through the tool interceptor chain.
+// Interceptors may rewrite Call (for example its Arguments) before calling next.
+type ToolInvocation struct {
+	TaskID    string
+	Iteration int
+	Call      llm.ToolCall
+}
+
+// ToolHandler performs (the remainder of) a tool dispatch.
+type ToolHandler func(ctx context.Context, inv *ToolInvocation) (string, error)
+
+// ToolInterceptor wraps a tool dispatch with the same contract as LLMInterceptor.
+type ToolInterceptor func(ctx context.Context, inv *ToolInvocation, next ToolHandler) (string, error)
+
+// UseLLMInterceptors appends interceptors around every LLM call. Interceptors
+// run in registration order, each wrapping the ones registered after it. The
+// built-in audit interceptor runs outermost and the input guard innermost, so
+// what the model receives has been screened after any rewrite by custom
+// interceptors.
+func (e *Engine) UseLLMInterceptors(interceptors ...LLMInterceptor) *Engine {
+	e.llmInterceptors = append(e.llmInterceptors, interceptors...)
+	return e
+}
+
+// UseToolInterceptors appends interceptors around every tool dispatch, in
+// registration order. The built-in audit and output guard interceptors run
+// outermost, so results short-circuited by a custom interceptor are still
+// screened; the approval gate runs innermost, so the operator approves the
+// call as it is finally dispatched.
+func (e *Engine) UseToolInterceptors(interceptors ...ToolInterceptor) *Engine {
+	e.toolInterceptors = append(e.toolInterceptors, interceptors...)
+	return e
+}
+
+// llmChain composes the built-in and registered LLM interceptors around final.
+func (e *Engine) llmChain(final LLMHandler) LLMHandler {
+	chain := make([]LLMInterceptor, 0, 2+len(e.llmInterceptors))
+	chain = append(chain, e.auditLLMCall)
+	chain = append(chain, e.llmInterceptors...)
+	chain = append(chain, e.guardLLMCall)
+
+	h := final
+	for i := len(chain) - 1; i >= 0; i-- {
+		ic, next := chain[i], h
+		h = func(ctx context.Context, call *LLMCall) (llm.LLMResponse, error) {
+			return ic(ctx, call, next)
+		}
+	}
+	return h
+}
+
+// toolChain composes the built-in and registered tool interceptors around final.
+func (e *Engine) toolChain(final ToolHandler) ToolHandler {
+	chain := make([]ToolInterceptor, 0, 3+len(e.toolInterceptors))
+	chain = append(chain, e.auditToolCall, e.guardToolCall)
+	chain = append(chain, e.toolInterceptors...)
+	chain = append(chain, e.approveToolCall)
+
+	h := final
+	for i := len(chain) - 1; i >= 0; i-- {
+		ic, next := chain[i], h
+		h = func(ctx context.Context, inv *ToolInvocation) (string, error) {
+			return ic(ctx, inv, next)
+		}
+	}
+	return h
+}
+
+// auditLLMCall is the built-in interceptor recording every LLM request in the audit chain.
+func (e *Engine) auditLLMCall(ctx context.Context, call *LLMCall, next LLMHandler) (llm.LLMResponse, error) {
+	e.logAudit(ctx, call.TaskID+"-req", "AUDIT_LLM_REQUEST", "engine",
+		map[string]interface{}{"task_id": call.TaskID, "messages_count": len(call.Messages)})
+	return next(ctx, call)
+}
+
+// guardLLMCall is the built-in interceptor screening the user input before the
+// task's first model turn. Tasks resumed from a checkpoint start at a later
+// iteration and were screened before the crash.
+func (e *Engine) guardLLMCall(ctx context.Context, call *LLMCall, next LLMHandler) (llm.LLMResponse, error) {
+	if call.Iteration != 0 || e.guard == nil {
+		return next(ctx, call)
+	}
+	input, ok := lastUserMessage(call.Messages)
+	if !ok {
+		return next(ctx, call)
+	}
+
+	guardRes, replayed := e.replay.inputVerdict()
+	if !replayed {
+		guardRes = e.guard.Scan(ctx, input, security.GuardConfig{})
+	}
+	e.recordGuard(call.TaskID, GuardStageInput, "", &guardRes)
+	if !guardRes.IsSafe {
+		WithTask(ctx, call.TaskID).Warn("security_violation_user_input",
+			slog.String("rule", guardRes.Violations[0].Category),
+			slog.String("description", guardRes.Violations[0].Description),
+		)
+		return llm.LLMResponse{}, fmt.Errorf("security_violation: %s", guardRes.Violations[0].Description)
+	}
+	return next(ctx, call)
+}
+
+// auditToolCall is the built-in interceptor recording tool dispatches and their
+// outcomes in the audit chain.
+func (e *Engine) auditToolCall(ctx context.Context, inv *ToolInvocation, next ToolHandler) (string, error) {
+	e.logAudit(ctx, inv.TaskID+"-tool-exec", "AUDIT_TOOL_EXECUTE", "engine",
+		map[string]interface{}{"task_id": inv.TaskID, "tool": inv.Call.Name})
+
+	start := time.Now()
+	out, err := next(ctx, inv)
+	if errors.Is(err, ErrCapabilityDenied) {
+		e.logAudit(ctx, inv.TaskID+"-tool-denied", "AUDIT_TOOL_DENIED", "engine",
+			map[string]interface{}{"task_id": inv.TaskID, "tool": inv.Call.Name, "reason": err.Error()})
+		return out, err
+	}
+
+	meta := map[string]interface{}{"task_id": inv.TaskID, "tool_name": inv.Call.Name, "duration_ms": time.Since(start).Milliseconds()}
+	if err != nil {
+		meta["error"] = err.Error()
+	}
+	e.logAudit(ctx, inv.TaskID+"-tool-res", "AUDIT_TOOL_RESULT", "engine", meta)
+	return out, err
+}
+
+// guardToolCall is the built-in interceptor screening tool output before it is
+// fed back to the model.
+func (e *Engine) guardToolCall(ctx context.Context, inv *ToolInvocation, next ToolHandler) (string, error) {
+	out, err := next(ctx, inv)
+	if err != nil {
+		return "", err
+	}
+	if scanErr := e.verifyToolOutput(ctx, inv.TaskID, inv.Call.Name, out); scanErr != nil {
+		return "", scanErr
+	}
+	return out, nil
+}
+
+// lastUserMessage returns the content of the most recent user message.
+func lastUserMessage(messages []llm.Message) (string, bool) {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			return messages[i].Content, true
+		}
+	}
+	return "", false
+}
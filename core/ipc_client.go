@@ -9,9 +9,15 @@ import (
 
 	"github.com/fzihak/aethercore/core/ipc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 )
 
+// ErrSandboxUnavailable marks sandbox calls that failed because the sandbox
+// could not be reached, as opposed to the sandbox rejecting the execution.
+var ErrSandboxUnavailable = errors.New("sandbox unavailable")
+
 // IPCSocketPath returns the OS-specific path for the Unix Domain Socket.
 func IPCSocketPath() string {
 	return filepath.Join(os.TempDir(), "aether-sandbox.sock")
@@ -54,7 +60,7 @@ func (c *SandboxClient) Close() error {
 // the sandbox reject invalid or missing signatures, avoiding a redundant gate.
 func (c *SandboxClient) ExecuteTool(ctx context.Context, toolName, payloadJSON, signatureHex string) (string, error) {
 	if c.client == nil {
-		return "", errors.New("sandbox client not connected: call NewSandboxClient first")
+		return "", fmt.Errorf("%w: client not connected: call NewSandboxClient first", ErrSandboxUnavailable)
 	}
 
 	req := &ipc.ToolRequest{
@@ -64,6 +70,9 @@ func (c *SandboxClient) ExecuteTool(ctx context.Context, toolName, payloadJSON,
 
 	res, err := c.client.ExecuteTool(ctx, req)
 	if err != nil {
+		if status.Code(err) == codes.Unavailable {
+			return "", fmt.Errorf("%w: rpc failure bridging to rust sandbox: %w", ErrSandboxUnavailable, err)
+		}
 		return "", fmt.Errorf("rpc failure bridging to rust sandbox: %w", err)
 	}
 
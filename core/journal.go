@@ -0,0 +1,311 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+// JournalEntryType classifies a write-ahead journal record.
+type JournalEntryType string
+
+const (
+	JournalSubmitted JournalEntryType = "submitted" // task accepted by the engine
+	JournalIteration JournalEntryType = "iteration" // a ReAct iteration completed
+	JournalCompleted JournalEntryType = "completed" // task reached a terminal state
+)
+
+// JournaledTask is the durable, serialisable subset of a Task.
+type JournaledTask struct {
+	ID        string      `json:"id"`
+	System    string      `json:"system,omitempty"`
+	Input     string      `json:"input"`
+	CreatedAt time.Time   `json:"created_at"`
+	Options   TaskOptions `json:"options"`
+}
+
+// JournalEntry is one record in the task journal.
+type JournalEntry struct {
+	Type      JournalEntryType `json:"type"`
+	TaskID    string           `json:"task_id"`
+	Timestamp time.Time        `json:"ts"`
+	Task      *JournaledTask   `json:"task,omitempty"`      // JournalSubmitted
+	Iteration int              `json:"iteration,omitempty"` // JournalIteration
+	Messages  []llm.Message    `json:"messages,omitempty"`  // JournalIteration: full history so far
+	Usage     llm.TokenUsage   `json:"usage"`               // JournalIteration: cumulative usage
+	Steps     []StepStatus     `json:"steps,omitempty"`     // JournalIteration: plan progress (plan_execute)
+	Error     string           `json:"error,omitempty"`     // JournalCompleted
+}
+
+// PendingTask is an unfinished task reconstructed from the journal, together
+// with its last checkpoint. Messages and Steps are nil when no iteration had
+// completed.
+type PendingTask struct {
+	Task      JournaledTask
+	Iteration int
+	Messages  []llm.Message
+	Usage     llm.TokenUsage
+	Steps     []StepStatus
+}
+
+// checkpointed reports whether the task had completed an iteration.
+func (p *PendingTask) checkpointed() bool {
+	return p.Messages != nil || p.Steps != nil
+}
+
+// Journal is a write-ahead log of task progress used for crash recovery.
+type Journal interface {
+	Append(entry *JournalEntry) error
+	Pending() ([]PendingTask, error)
+}
+
+// FileJournal is a Journal backed by an append-only JSONL file. Every append
+// is fsynced before returning. Open compacts the file down to the records of
+// unfinished tasks so it does not grow without bound across restarts.
+type FileJournal struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// NewFileJournal returns a journal writing to path. Call Open before use.
+func NewFileJournal(path string) *FileJournal {
+	return &FileJournal{path: path}
+}
+
+// Open compacts any existing journal at the configured path and opens it for appending.
+func (j *FileJournal) Open() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.compact(); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("journal_open_failed: %w", err)
+	}
+	j.file = f
+	return nil
+}
+
+// Close releases the underlying file.
+func (j *FileJournal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+// Append durably writes one entry.
+func (j *FileJournal) Append(entry *JournalEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("journal_marshal_failed: %w", err)
+	}
+	data = append(data, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil {
+		return errors.New("journal not open: call Open first")
+	}
+	if _, err := j.file.Write(data); err != nil {
+		return fmt.Errorf("journal_write_failed: %w", err)
+	}
+	return j.file.Sync()
+}
+
+// Pending replays the journal and returns every task without a completion record.
+func (j *FileJournal) Pending() ([]PendingTask, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	pending, _, err := j.replay()
+	return pending, err
+}
+
+// replay folds the journal into the pending task set, preserving submission order.
+func (j *FileJournal) replay() ([]PendingTask, []string, error) {
+	f, err := os.Open(j.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("journal_read_failed: %w", err)
+	}
+	defer f.Close()
+
+	state := make(map[string]*PendingTask)
+	var order []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A torn final write from a crash is expected; skip it.
+			slog.Warn("journal_record_skipped", slog.String("error", err.Error()))
+			continue
+		}
+		switch entry.Type {
+		case JournalSubmitted:
+			if entry.Task != nil {
+				state[entry.TaskID] = &PendingTask{Task: *entry.Task}
+				order = append(order, entry.TaskID)
+			}
+		case JournalIteration:
+			if p, ok := state[entry.TaskID]; ok {
+				p.Iteration = entry.Iteration
+				p.Messages = entry.Messages
+				p.Usage = entry.Usage
+				p.Steps = entry.Steps
+			}
+		case JournalCompleted:
+			delete(state, entry.TaskID)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("journal_read_failed: %w", err)
+	}
+
+	pending := make([]PendingTask, 0, len(state))
+	kept := make([]string, 0, len(state))
+	for _, id := range order {
+		if p, ok := state[id]; ok {
+			pending = append(pending, *p)
+			kept = append(kept, id)
+			delete(state, id) // guard against duplicate submissions of one ID
+		}
+	}
+	return pending, kept, nil
+}
+
+// compact rewrites the journal so it contains only the latest state of each
+// unfinished task. The rewrite is atomic via rename.
+func (j *FileJournal) compact() error {
+	pending, _, err := j.replay()
+	if err != nil || pending == nil {
+		return err
+	}
+
+	tmp := j.path + ".compact"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("journal_compact_failed: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for i := range pending {
+		p := &pending[i]
+		task := p.Task
+		entries := []JournalEntry{{Type: JournalSubmitted, TaskID: task.ID, Timestamp: time.Now(), Task: &task}}
+		if p.checkpointed() {
+			entries = append(entries, JournalEntry{
+				Type: JournalIteration, TaskID: task.ID, Timestamp: time.Now(),
+				Iteration: p.Iteration, Messages: p.Messages, Usage: p.Usage, Steps: p.Steps,
+			})
+		}
+		for k := range entries {
+			if err := enc.Encode(&entries[k]); err != nil {
+				_ = f.Close()
+				return fmt.Errorf("journal_compact_failed: %w", err)
+			}
+		}
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("journal_compact_failed: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("journal_compact_failed: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("journal_compact_failed: %w", err)
+	}
+	return os.Rename(tmp, j.path)
+}
+
+// resumeState carries a recovered checkpoint into executeEphemeral.
+type resumeState struct {
+	iteration int // last completed iteration
+	messages  []llm.Message
+	usage     llm.TokenUsage
+	steps     []StepStatus // plan_execute progress
+}
+
+// WithJournal attaches a write-ahead journal. Submissions, completed ReAct
+// iterations and completions are recorded so that Recover can resume
+// unfinished tasks after a restart.
+func (e *Engine) WithJournal(j Journal) *Engine {
+	e.journal = j
+	return e
+}
+
+// Recover re-enqueues every unfinished task found in the journal. Tasks with a
+// checkpoint resume after their last completed iteration, so tool calls from
+// completed iterations are not executed again; only an iteration that was
+// interrupted mid-flight is repeated. It returns the number of tasks enqueued.
+// Tasks that cannot be enqueued, e.g. because the queue or their tenant's
+// quota is full, stay pending: calling Recover again once there is room
+// resubmits them and skips the tasks already in flight.
+func (e *Engine) Recover() (int, error) {
+	if e.journal == nil {
+		return 0, nil
+	}
+	pending, err := e.journal.Pending()
+	if err != nil {
+		return 0, err
+	}
+
+	enqueued := 0
+	for i := range pending {
+		p := &pending[i]
+		if h, ok := e.tracker.get(p.Task.ID); ok && !h.snapshot().State.Terminal() {
+			continue
+		}
+		t := e.GetTask()
+		t.ID = p.Task.ID
+		t.System = p.Task.System
+		t.Input = p.Task.Input
+		t.CreatedAt = p.Task.CreatedAt
+		t.Options = p.Task.Options
+		if p.checkpointed() {
+			t.resume = &resumeState{iteration: p.Iteration, messages: p.Messages, usage: p.Usage, steps: p.Steps}
+		}
+		if err := e.enqueue(context.Background(), t, false, false); err != nil {
+			return enqueued, fmt.Errorf("journal_recover_failed after %d of %d tasks: %w", i, len(pending), err)
+		}
+		enqueued++
+		WithTask(context.Background(), t.ID).Info("journal_task_recovered", slog.Bool("checkpointed", t.resume != nil))
+	}
+	return enqueued, nil
+}
+
+// journalAppend records an entry, logging rather than failing the task on error.
+func (e *Engine) journalAppend(entry *JournalEntry) {
+	if e.journal == nil {
+		return
+	}
+	entry.Timestamp = time.Now()
+	if err := e.journal.Append(entry); err != nil {
+		WithComponent("journal").Error("journal_append_failed",
+			slog.String("task_id", entry.TaskID),
+			slog.String("type", string(entry.Type)),
+			slog.String("error", err.Error()),
+		)
+	}
+}
@@ -0,0 +1,187 @@
+package core
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+func openTestJournal(t *testing.T, path string) *FileJournal {
+	t.Helper()
+	j := NewFileJournal(path)
+	if err := j.Open(); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { _ = j.Close() })
+	return j
+}
+
+func countLines(t *testing.T, path string) int {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer f.Close()
+	n := 0
+	for s := bufio.NewScanner(f); s.Scan(); {
+		n++
+	}
+	return n
+}
+
+func TestFileJournal_pendingAndCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.journal")
+	j := openTestJournal(t, path)
+
+	history := []llm.Message{{Role: "system", Content: "s"}, {Role: "user", Content: "u"}}
+	for _, e := range []*JournalEntry{
+		{Type: JournalSubmitted, TaskID: "a", Task: &JournaledTask{ID: "a", Input: "first", Options: TaskOptions{MaxIterations: 3}}},
+		{Type: JournalSubmitted, TaskID: "b", Task: &JournaledTask{ID: "b", Input: "second"}},
+		{Type: JournalIteration, TaskID: "a", Iteration: 0, Messages: history, Usage: llm.TokenUsage{TotalTokens: 7}},
+		{Type: JournalIteration, TaskID: "a", Iteration: 1, Messages: history, Usage: llm.TokenUsage{TotalTokens: 9}},
+		{Type: JournalCompleted, TaskID: "b"},
+	} {
+		if err := j.Append(e); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	_ = j.Close()
+
+	// Simulate a crash mid-write.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"type":"completed","task_id":"a"`)
+	_ = f.Close()
+
+	j = openTestJournal(t, path)
+	pending, err := j.Pending()
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if len(pending) != 1 || pending[0].Task.ID != "a" {
+		t.Fatalf("want only task a pending, got %+v", pending)
+	}
+	p := pending[0]
+	if p.Iteration != 1 || len(p.Messages) != 2 || p.Usage.TotalTokens != 9 || p.Task.Options.MaxIterations != 3 {
+		t.Errorf("latest checkpoint not restored: %+v", p)
+	}
+	if n := countLines(t, path); n != 2 {
+		t.Errorf("compacted journal should hold submit + checkpoint, got %d lines", n)
+	}
+}
+
+func TestEngine_journalRecordsCompletion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.journal")
+	j := openTestJournal(t, path)
+
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{
+		{ToolCalls: []llm.ToolCall{{ID: "c1", Name: "count"}}},
+		{Content: "done"},
+	}}
+	engine := NewEngine(adapter, 1, 1).WithJournal(j)
+	registerAll(t, engine, &countingTool{name: "count"})
+
+	res := runSingleTask(t, engine, &Task{ID: "journaled", Input: "go", CreatedAt: time.Now()})
+	if res.Error != nil {
+		t.Fatalf("unexpected error: %v", res.Error)
+	}
+
+	pending, err := j.Pending()
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("completed task must not be pending: %+v", pending)
+	}
+	if n := countLines(t, path); n != 3 {
+		t.Errorf("want submitted, iteration and completed records, got %d lines", n)
+	}
+}
+
+func TestEngine_recoverResumesAfterCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.journal")
+	j := openTestJournal(t, path)
+
+	checkpoint := []llm.Message{
+		{Role: "system", Content: defaultSystemPrompt},
+		{Role: "user", Content: "count things"},
+		{Role: "assistant", ToolCalls: []llm.ToolCall{{ID: "c1", Name: "count"}}},
+		{Role: "tool", ToolResults: []llm.ToolResultMessage{{ToolCallID: "c1", Content: "count ran"}}},
+	}
+	for _, e := range []*JournalEntry{
+		{Type: JournalSubmitted, TaskID: "crashed", Task: &JournaledTask{ID: "crashed", Input: "count things"}},
+		{Type: JournalIteration, TaskID: "crashed", Iteration: 0, Messages: checkpoint, Usage: llm.TokenUsage{TotalTokens: 40}},
+	} {
+		if err := j.Append(e); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{{Content: "resumed", TokenUsage: llm.TokenUsage{TotalTokens: 10}}}}
+	tool := &countingTool{name: "count"}
+	engine := NewEngine(adapter, 1, 1).WithJournal(j)
+	registerAll(t, engine, tool)
+
+	n, err := engine.Recover()
+	if err != nil || n != 1 {
+		t.Fatalf("Recover: n=%d err=%v", n, err)
+	}
+	engine.Start()
+	defer engine.Stop()
+	res := <-engine.Results()
+
+	if res.Error != nil || res.Output != "resumed" || res.TaskID != "crashed" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if tool.count() != 0 {
+		t.Errorf("tool from the completed iteration was re-executed %d times", tool.count())
+	}
+	if adapter.callCount() != 1 || len(adapter.requests[0]) != len(checkpoint) {
+		t.Errorf("LLM should see the restored history, got %d calls", adapter.callCount())
+	}
+	if res.Usage.TotalTokens != 50 {
+		t.Errorf("usage should continue from checkpoint, got %d", res.Usage.TotalTokens)
+	}
+
+	if pending, _ := j.Pending(); len(pending) != 0 {
+		t.Errorf("recovered task should be completed in the journal: %+v", pending)
+	}
+}
+
+func TestEngine_recoverKeepsTasksItCannotQueue(t *testing.T) {
+	j := openTestJournal(t, filepath.Join(t.TempDir(), "tasks.journal"))
+	for _, id := range []string{"first", "second"} {
+		task := &JournaledTask{ID: id, Input: "go", Options: TaskOptions{Tenant: "acme"}}
+		if err := j.Append(&JournalEntry{Type: JournalSubmitted, TaskID: id, Task: task}); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	engine := NewEngine(&scriptedLLM{responses: []llm.LLMResponse{{Content: "ok"}}}, 1, 4).
+		WithJournal(j).WithScheduling(SchedulingConfig{MaxTenantTasks: 1})
+
+	if n, err := engine.Recover(); n != 1 || !errors.Is(err, ErrTenantQuotaExceeded) {
+		t.Fatalf("want the tenant quota to stop recovery after one task, got n=%d err=%v", n, err)
+	}
+	if pending, _ := j.Pending(); len(pending) != 2 {
+		t.Fatalf("a task that could not be queued must stay pending, got %+v", pending)
+	}
+
+	// Free the tenant's slot without finishing "first": a repeated Recover
+	// must queue "second" and skip the task already in flight.
+	<-engine.queue.ready
+	engine.queue.done(engine.queue.pop().Options.Tenant)
+	if n, err := engine.Recover(); err != nil || n != 1 {
+		t.Fatalf("Recover once the quota frees up: n=%d err=%v", n, err)
+	}
+	if got := engine.queue.pop(); got == nil || got.ID != "second" || engine.queue.pop() != nil {
+		t.Errorf("only the task left behind should be queued, got %+v", got)
+	}
+}
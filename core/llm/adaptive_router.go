@@ -0,0 +1,232 @@
+package llm
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLatencyAlpha  = 0.2
+	defaultLatencyWindow = 100
+	defaultExploreRate   = 0.05
+	latencyPercentile    = 0.95
+)
+
+// AdaptiveLatencyConfig tunes an AdaptiveLatencyRouter. Zero fields take the
+// defaults noted.
+type AdaptiveLatencyConfig struct {
+	// Alpha is the EWMA weight of the newest observation (default 0.2).
+	Alpha float64
+	// Window is how many recent observations the p95 covers (default 100).
+	Window int
+	// ExploreRate is the probability that Select picks a random other usable
+	// provider instead of the fastest, keeping its stats fresh (default 0.05;
+	// negative disables exploration).
+	ExploreRate float64
+	// RankByP95 ranks providers by p95 instead of EWMA latency, favouring
+	// consistent providers over ones that are fast on average.
+	RankByP95 bool
+}
+
+// LatencyStats is one observed latency metric of a provider.
+type LatencyStats struct {
+	EWMA         time.Duration
+	P95          time.Duration
+	Samples      int // observations in the p95 window
+	LastObserved time.Time
+}
+
+// ProviderLatency is the observed latency of one provider. The two metrics
+// are not comparable and are tracked separately.
+type ProviderLatency struct {
+	TimeToFirstToken LatencyStats // streamed calls
+	Total            LatencyStats // non-streamed calls
+}
+
+// AdaptiveLatencyRouter selects the provider with the lowest observed latency.
+// Latency is measured on calls made through the providers Select returns:
+// time to first token for streamed calls, total time otherwise. Streamed turns
+// (see Orchestrator) are ranked by time to first token, others by total time.
+// Failed calls are not measured; wrap providers in a HealthProvider to route
+// around errors. Providers with no observations of the relevant kind are
+// ranked by ModelMetadata.LatencyMillis.
+type AdaptiveLatencyRouter struct {
+	providers []Provider // *measuredProvider
+	cfg       AdaptiveLatencyConfig
+	random    func() float64
+	intn      func(n int) int
+
+	mu sync.Mutex // guards the measuredProvider trackers
+}
+
+// latencyTracker accumulates one latency metric's observations.
+type latencyTracker struct {
+	ewma    float64 // nanoseconds
+	window  []time.Duration
+	next    int
+	lastObs time.Time
+}
+
+// NewAdaptiveLatencyRouter routes among providers by observed latency.
+func NewAdaptiveLatencyRouter(providers []Provider, cfg AdaptiveLatencyConfig) *AdaptiveLatencyRouter {
+	if cfg.Alpha <= 0 || cfg.Alpha > 1 {
+		cfg.Alpha = defaultLatencyAlpha
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = defaultLatencyWindow
+	}
+	if cfg.ExploreRate == 0 {
+		cfg.ExploreRate = defaultExploreRate
+	}
+	r := &AdaptiveLatencyRouter{
+		cfg:    cfg,
+		random: rand.Float64,
+		intn:   rand.IntN,
+	}
+	for _, p := range providers {
+		r.providers = append(r.providers, &measuredProvider{Provider: p, router: r})
+	}
+	return r
+}
+
+// Select returns the usable provider with the lowest expected latency, or
+// occasionally another usable provider to explore.
+func (r *AdaptiveLatencyRouter) Select(ctx context.Context, task string) (Provider, error) {
+	candidates := usable(ctx, r.providers)
+	if len(candidates) == 0 {
+		return nil, ErrNoHealthyProvider
+	}
+
+	streamed := streamingFrom(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	expected := make(map[Provider]time.Duration, len(candidates))
+	for _, p := range candidates {
+		expected[p] = r.expectedLocked(p.(*measuredProvider), streamed)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return expected[candidates[i]] < expected[candidates[j]]
+	})
+	if len(candidates) > 1 && r.cfg.ExploreRate > 0 && r.random() < r.cfg.ExploreRate {
+		return candidates[1+r.intn(len(candidates)-1)], nil
+	}
+	return candidates[0], nil
+}
+
+// Stats returns the observed latency of each provider, keyed by the provider
+// as passed to NewAdaptiveLatencyRouter.
+func (r *AdaptiveLatencyRouter) Stats() map[Provider]ProviderLatency {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make(map[Provider]ProviderLatency, len(r.providers))
+	for _, p := range r.providers {
+		m := p.(*measuredProvider)
+		out[m.Provider] = ProviderLatency{TimeToFirstToken: m.ttft.stats(), Total: m.total.stats()}
+	}
+	return out
+}
+
+// expectedLocked is the latency used to rank m for a streamed or plain call.
+func (r *AdaptiveLatencyRouter) expectedLocked(m *measuredProvider, streamed bool) time.Duration {
+	t := m.tracker(streamed)
+	switch {
+	case len(t.window) == 0:
+		return time.Duration(m.Metadata().LatencyMillis) * time.Millisecond
+	case r.cfg.RankByP95:
+		return t.p95()
+	default:
+		return time.Duration(t.ewma)
+	}
+}
+
+// observe records a successful call's latency for m.
+func (r *AdaptiveLatencyRouter) observe(m *measuredProvider, streamed bool, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := m.tracker(streamed)
+	if len(t.window) == 0 {
+		t.ewma = float64(d)
+	} else {
+		t.ewma += r.cfg.Alpha * (float64(d) - t.ewma)
+	}
+	if len(t.window) < r.cfg.Window {
+		t.window = append(t.window, d)
+	} else {
+		t.window[t.next] = d
+		t.next = (t.next + 1) % r.cfg.Window
+	}
+	t.lastObs = time.Now()
+}
+
+func (t *latencyTracker) stats() LatencyStats {
+	return LatencyStats{EWMA: time.Duration(t.ewma), P95: t.p95(), Samples: len(t.window), LastObserved: t.lastObs}
+}
+
+func (t *latencyTracker) p95() time.Duration {
+	if len(t.window) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), t.window...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	idx := int(math.Ceil(float64(len(sorted))*latencyPercentile)) - 1
+	return sorted[max(idx, 0)]
+}
+
+// measuredProvider times calls to the wrapped provider for its router.
+type measuredProvider struct {
+	Provider
+	router *AdaptiveLatencyRouter
+	ttft   latencyTracker // guarded by router.mu
+	total  latencyTracker // guarded by router.mu
+}
+
+func (m *measuredProvider) tracker(streamed bool) *latencyTracker {
+	if streamed {
+		return &m.ttft
+	}
+	return &m.total
+}
+
+func (m *measuredProvider) Generate(ctx context.Context, systemPrompt, userInput string) (string, error) {
+	start := time.Now()
+	out, err := m.Provider.Generate(ctx, systemPrompt, userInput)
+	if err == nil {
+		m.router.observe(m, false, time.Since(start))
+	}
+	return out, err
+}
+
+func (m *measuredProvider) GenerateWithTools(ctx context.Context, messages []Message, tools []ToolManifest) (LLMResponse, error) {
+	start := time.Now()
+	res, err := m.Provider.GenerateWithTools(ctx, messages, tools)
+	if err == nil {
+		m.router.observe(m, false, time.Since(start))
+	}
+	return res, err
+}
+
+// GenerateWithToolsStream records time to first token, or the total time for
+// a turn that produced no content (such as a bare tool call).
+func (m *measuredProvider) GenerateWithToolsStream(ctx context.Context, messages []Message, tools []ToolManifest, onChunk func(StreamChunk)) (LLMResponse, error) {
+	start := time.Now()
+	var ttft time.Duration
+	res, err := GenerateStream(ctx, m.Provider, messages, tools, func(c StreamChunk) {
+		if ttft == 0 {
+			ttft = time.Since(start)
+		}
+		if onChunk != nil {
+			onChunk(c)
+		}
+	})
+	if err == nil {
+		if ttft == 0 {
+			ttft = time.Since(start)
+		}
+		m.router.observe(m, true, ttft)
+	}
+	return res, err
+}
@@ -0,0 +1,143 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// slowStreamAdapter streams its first chunk at once, then stalls before finishing.
+type slowStreamAdapter struct {
+	stubAdapter
+	stall time.Duration
+}
+
+func (a *slowStreamAdapter) GenerateWithToolsStream(_ context.Context, _ []Message, _ []ToolManifest, onChunk func(StreamChunk)) (LLMResponse, error) {
+	onChunk(StreamChunk{Content: "first"})
+	time.Sleep(a.stall)
+	return LLMResponse{Content: "first rest"}, nil
+}
+
+// measured returns the router's wrapper around its i-th provider.
+func measured(r *AdaptiveLatencyRouter, i int) *measuredProvider {
+	return r.providers[i].(*measuredProvider)
+}
+
+func selectName(t *testing.T, r *AdaptiveLatencyRouter) string {
+	t.Helper()
+	p, err := r.Select(context.Background(), "task")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.Name()
+}
+
+func TestAdaptiveLatencyRouter_observationsOverrideMetadata(t *testing.T) {
+	gpu := NewProvider(&stubAdapter{name: "gpu-box"}, 1, ModelMetadata{LatencyMillis: 100})
+	cloud := NewProvider(&stubAdapter{name: "cloud"}, 1, ModelMetadata{LatencyMillis: 800})
+	r := NewAdaptiveLatencyRouter([]Provider{gpu, cloud}, AdaptiveLatencyConfig{ExploreRate: -1})
+
+	if got := selectName(t, r); got != "gpu-box" {
+		t.Fatalf("without observations metadata decides, got %s", got)
+	}
+	r.observe(measured(r, 0), false, 3*time.Second) // the box is busy
+	if got := selectName(t, r); got != "cloud" {
+		t.Fatalf("observed latency must beat static metadata, got %s", got)
+	}
+	r.observe(measured(r, 1), false, 5*time.Second)
+	if got := selectName(t, r); got != "gpu-box" {
+		t.Fatalf("want the lower observed EWMA, got %s", got)
+	}
+}
+
+func TestAdaptiveLatencyRouter_measuresCallsAndSkipsFailures(t *testing.T) {
+	ok := NewProvider(&stubAdapter{name: "ok"}, 1, ModelMetadata{})
+	failing := NewProvider(&stubAdapter{name: "failing", err: errors.New("boom")}, 1, ModelMetadata{})
+	r := NewAdaptiveLatencyRouter([]Provider{ok, failing}, AdaptiveLatencyConfig{})
+
+	for _, p := range r.providers {
+		_, _ = p.GenerateWithTools(context.Background(), nil, nil)
+	}
+	stats := r.Stats()
+	if got := stats[ok].Total; got.Samples != 1 || got.LastObserved.IsZero() {
+		t.Errorf("successful call must be recorded, got %+v", got)
+	}
+	if got := stats[failing].Total; got.Samples != 0 {
+		t.Errorf("failed calls must not be recorded, got %+v", got)
+	}
+}
+
+func TestAdaptiveLatencyRouter_streamRecordsTimeToFirstToken(t *testing.T) {
+	stream := NewProvider(&slowStreamAdapter{stubAdapter: stubAdapter{name: "stream"}, stall: 100 * time.Millisecond}, 1, ModelMetadata{})
+	r := NewAdaptiveLatencyRouter([]Provider{stream}, AdaptiveLatencyConfig{})
+
+	var chunks int
+	if _, err := GenerateStream(context.Background(), r.providers[0], nil, nil, func(StreamChunk) { chunks++ }); err != nil {
+		t.Fatal(err)
+	}
+	got := r.Stats()[stream]
+	if chunks != 1 || got.TimeToFirstToken.Samples != 1 || got.TimeToFirstToken.EWMA >= 50*time.Millisecond {
+		t.Errorf("want time to first token well under the 100ms stall, got %+v (%d chunks)", got, chunks)
+	}
+	if got.Total.Samples != 0 {
+		t.Errorf("time to first token must not mix with total latency, got %+v", got.Total)
+	}
+}
+
+func TestAdaptiveLatencyRouter_ranksByCallTypeAndProviderIdentity(t *testing.T) {
+	// Two boxes serving the same model share a name but not their latency.
+	snappy := NewProvider(&stubAdapter{name: "ollama/llama3"}, 1, ModelMetadata{})
+	steady := NewProvider(&stubAdapter{name: "ollama/llama3"}, 1, ModelMetadata{})
+	r := NewAdaptiveLatencyRouter([]Provider{snappy, steady}, AdaptiveLatencyConfig{ExploreRate: -1})
+	r.observe(measured(r, 0), true, 50*time.Millisecond) // quick first token...
+	r.observe(measured(r, 0), false, 4*time.Second)      // ...but long answers
+	r.observe(measured(r, 1), true, 900*time.Millisecond)
+	r.observe(measured(r, 1), false, time.Second)
+
+	if p, _ := r.Select(withStreaming(context.Background()), "task"); p != r.providers[0] {
+		t.Error("streamed turns should be ranked by time to first token")
+	}
+	if p, _ := r.Select(context.Background(), "task"); p != r.providers[1] {
+		t.Error("plain turns should be ranked by total latency")
+	}
+	if stats := r.Stats(); stats[snappy].Total.EWMA != 4*time.Second || stats[steady].Total.EWMA != time.Second {
+		t.Errorf("same-named providers must be tracked separately, got %+v", stats)
+	}
+}
+
+func TestAdaptiveLatencyRouter_explores(t *testing.T) {
+	fast := NewProvider(&stubAdapter{name: "fast"}, 1, ModelMetadata{LatencyMillis: 10})
+	slow := NewProvider(&stubAdapter{name: "slow"}, 1, ModelMetadata{LatencyMillis: 900})
+	r := NewAdaptiveLatencyRouter([]Provider{slow, fast}, AdaptiveLatencyConfig{ExploreRate: 0.1})
+
+	r.random = func() float64 { return 0.5 }
+	if got := selectName(t, r); got != "fast" {
+		t.Fatalf("want exploitation, got %s", got)
+	}
+	r.random = func() float64 { return 0.05 }
+	r.intn = func(int) int { return 0 }
+	if got := selectName(t, r); got != "slow" {
+		t.Fatalf("want exploration of the slower provider, got %s", got)
+	}
+
+	slow.SetStatus(StatusOffline)
+	if got := selectName(t, r); got != "fast" {
+		t.Fatalf("exploration must not pick offline providers, got %s", got)
+	}
+}
+
+func TestLatencyTracker_p95(t *testing.T) {
+	p := NewProvider(&stubAdapter{name: "p"}, 1, ModelMetadata{})
+	r := NewAdaptiveLatencyRouter([]Provider{p}, AdaptiveLatencyConfig{Window: 100, RankByP95: true})
+	for i := 1; i <= 100; i++ {
+		r.observe(measured(r, 0), false, time.Duration(i)*time.Millisecond)
+	}
+	if got := r.Stats()[p].Total; got.P95 != 95*time.Millisecond || got.Samples != 100 {
+		t.Errorf("want p95 95ms over 100 samples, got %+v", got)
+	}
+	r.observe(measured(r, 0), false, time.Second) // evicts the 1ms sample
+	if got := r.Stats()[p].Total; got.Samples != 100 || got.P95 != 96*time.Millisecond {
+		t.Errorf("window must stay bounded, got %+v", got)
+	}
+}
@@ -0,0 +1,346 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	anthropicAPIVersion       = "2023-06-01"
+	anthropicDefaultMaxTokens = 4096
+)
+
+// AnthropicAdapter implements LLMAdapter against the Anthropic Messages API.
+// Tool calls travel as native tool_use and tool_result content blocks, and
+// system turns are lifted into the top-level system field.
+//
+// API reference: https://docs.anthropic.com/en/api/messages
+type AnthropicAdapter struct {
+	model     string
+	baseURL   string // overridable for tests via newTestAnthropicAdapter
+	apiKey    string
+	maxTokens int
+	http      *http.Client
+
+	mu        sync.Mutex
+	rateLimit AnthropicRateLimit
+	hasLimit  bool
+}
+
+// AnthropicRateLimit is the rate-limit state reported by the most recent
+// response. Zero fields were absent from the response headers.
+type AnthropicRateLimit struct {
+	RequestsLimit     int
+	RequestsRemaining int
+	RequestsReset     time.Time
+	TokensLimit       int
+	TokensRemaining   int
+	TokensReset       time.Time
+}
+
+// NewAnthropicAdapter constructs an adapter for the public Anthropic API.
+func NewAnthropicAdapter(apiKey, model string) *AnthropicAdapter {
+	return NewAnthropicAdapterWithURL("https://api.anthropic.com", apiKey, model)
+}
+
+// NewAnthropicAdapterWithURL constructs an adapter pointing at the provided base
+// URL, for proxies and gateways that expose the Messages API.
+func NewAnthropicAdapterWithURL(baseURL, apiKey, model string) *AnthropicAdapter {
+	return &AnthropicAdapter{
+		model:     model,
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		apiKey:    apiKey,
+		maxTokens: anthropicDefaultMaxTokens,
+		http:      &http.Client{Timeout: 120 * time.Second},
+	}
+}
+
+// newTestAnthropicAdapter constructs an adapter pointing at a test server (e.g. httptest.Server).
+func newTestAnthropicAdapter(model, baseURL string) *AnthropicAdapter {
+	return &AnthropicAdapter{
+		model:     model,
+		baseURL:   baseURL,
+		apiKey:    "test-key",
+		maxTokens: anthropicDefaultMaxTokens,
+		http:      &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// WithMaxTokens sets the per-turn output token limit (default 4096).
+func (a *AnthropicAdapter) WithMaxTokens(n int) *AnthropicAdapter {
+	if n > 0 {
+		a.maxTokens = n
+	}
+	return a
+}
+
+// Name returns the adapter identifier used in routing tables.
+func (a *AnthropicAdapter) Name() string { return "anthropic/" + a.model }
+
+// RateLimit returns the rate-limit state from the latest response, and false
+// if no response has carried rate-limit headers yet.
+func (a *AnthropicAdapter) RateLimit() (AnthropicRateLimit, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.rateLimit, a.hasLimit
+}
+
+// Generate is a convenience wrapper for single-turn text generation.
+func (a *AnthropicAdapter) Generate(ctx context.Context, systemPrompt, userInput string) (string, error) {
+	msgs := []Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userInput},
+	}
+	res, err := a.GenerateWithTools(ctx, msgs, nil)
+	if err != nil {
+		return "", err
+	}
+	return res.Content, nil
+}
+
+// GenerateWithTools sends a Messages API request with tool definitions. Text
+// blocks in the reply are concatenated into Content; tool_use blocks become
+// ToolCalls carrying the API-issued IDs.
+func (a *AnthropicAdapter) GenerateWithTools(ctx context.Context, messages []Message, tools []ToolManifest) (LLMResponse, error) {
+	payload, err := json.Marshal(a.buildRequest(ctx, messages, tools))
+	if err != nil {
+		return LLMResponse{}, fmt.Errorf("anthropic: marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL+"/v1/messages", bytes.NewReader(payload))
+	if err != nil {
+		return LLMResponse{}, fmt.Errorf("anthropic: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Api-Key", a.apiKey)
+	req.Header.Set("Anthropic-Version", anthropicAPIVersion)
+
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return LLMResponse{}, fmt.Errorf("anthropic: POST /v1/messages: %w", err)
+	}
+	defer resp.Body.Close()
+	a.recordRateLimit(resp.Header)
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return LLMResponse{}, fmt.Errorf("anthropic: read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return LLMResponse{}, &StatusError{
+			Provider: "anthropic", StatusCode: resp.StatusCode, Body: string(raw), RetryAfter: retryAfter(resp.Header),
+		}
+	}
+
+	var msgResp anthropicResponse
+	if err := json.Unmarshal(raw, &msgResp); err != nil {
+		return LLMResponse{}, fmt.Errorf("anthropic: decode response: %w", err)
+	}
+	return fromAnthropicResponse(msgResp), nil
+}
+
+// buildRequest converts engine-internal messages and manifests into the
+// /v1/messages request body.
+func (a *AnthropicAdapter) buildRequest(ctx context.Context, messages []Message, tools []ToolManifest) anthropicRequest {
+	reqBody := anthropicRequest{Model: a.modelFor(ctx), MaxTokens: a.maxTokens}
+
+	var system []string
+	for _, m := range messages {
+		if m.Role == "system" {
+			if m.Content != "" {
+				system = append(system, m.Content)
+			}
+			continue
+		}
+		reqBody.Messages = appendAnthropicMessage(reqBody.Messages, m)
+	}
+	reqBody.System = strings.Join(system, "\n\n")
+
+	for _, t := range tools {
+		reqBody.Tools = append(reqBody.Tools, toAnthropicTool(t))
+	}
+	return reqBody
+}
+
+// modelFor resolves the model to request. A context hint of the form "name" or
+// "anthropic/name" overrides the configured model; hints addressed to other
+// providers are ignored.
+func (a *AnthropicAdapter) modelFor(ctx context.Context) string {
+	if model := hintedModel(ModelHintFromContext(ctx), "anthropic"); model != "" {
+		return model
+	}
+	return a.model
+}
+
+// recordRateLimit captures the anthropic-ratelimit-* headers, if present.
+func (a *AnthropicAdapter) recordRateLimit(h http.Header) {
+	if h.Get("Anthropic-Ratelimit-Requests-Limit") == "" && h.Get("Anthropic-Ratelimit-Tokens-Limit") == "" {
+		return
+	}
+	atoi := func(key string) int {
+		n, _ := strconv.Atoi(h.Get(key))
+		return n
+	}
+	reset := func(key string) time.Time {
+		t, _ := time.Parse(time.RFC3339, h.Get(key))
+		return t
+	}
+	rl := AnthropicRateLimit{
+		RequestsLimit:     atoi("Anthropic-Ratelimit-Requests-Limit"),
+		RequestsRemaining: atoi("Anthropic-Ratelimit-Requests-Remaining"),
+		RequestsReset:     reset("Anthropic-Ratelimit-Requests-Reset"),
+		TokensLimit:       atoi("Anthropic-Ratelimit-Tokens-Limit"),
+		TokensRemaining:   atoi("Anthropic-Ratelimit-Tokens-Remaining"),
+		TokensReset:       reset("Anthropic-Ratelimit-Tokens-Reset"),
+	}
+	a.mu.Lock()
+	a.rateLimit, a.hasLimit = rl, true
+	a.mu.Unlock()
+}
+
+// ---- Anthropic wire types ----------------------------------------------------
+
+// anthropicRequest is the JSON body for POST /v1/messages.
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	MaxTokens int                `json:"max_tokens"`
+	System    string             `json:"system,omitempty"`
+	Messages  []anthropicMessage `json:"messages"`
+	Tools     []anthropicTool    `json:"tools,omitempty"`
+}
+
+// anthropicMessage is a single turn; the API requires user and assistant
+// turns to alternate.
+type anthropicMessage struct {
+	Role    string           `json:"role"` // "user" or "assistant"
+	Content []anthropicBlock `json:"content"`
+}
+
+// anthropicBlock is a content block of type text, tool_use or tool_result.
+type anthropicBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`          // tool_use
+	Name      string          `json:"name,omitempty"`        // tool_use
+	Input     json.RawMessage `json:"input,omitempty"`       // tool_use
+	ToolUseID string          `json:"tool_use_id,omitempty"` // tool_result
+	Content   string          `json:"content,omitempty"`     // tool_result
+	IsError   bool            `json:"is_error,omitempty"`    // tool_result
+}
+
+// anthropicTool describes a tool in the Messages API format.
+type anthropicTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+// anthropicResponse is the JSON envelope returned by POST /v1/messages.
+type anthropicResponse struct {
+	ID         string           `json:"id"`
+	Model      string           `json:"model"`
+	Content    []anthropicBlock `json:"content"`
+	StopReason string           `json:"stop_reason"`
+	Usage      anthropicUsage   `json:"usage"`
+}
+
+type anthropicUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+// ---- conversion helpers -----------------------------------------------------
+
+// appendAnthropicMessage converts m to content blocks and appends them,
+// merging into the previous turn when the roles match so that tool results
+// and a following user message still alternate with assistant turns.
+//
+//nolint:gocritic // hugeParam requires pointer but Message is heavily used as value in Layer 0
+func appendAnthropicMessage(msgs []anthropicMessage, m Message) []anthropicMessage {
+	role := "user"
+	var blocks []anthropicBlock
+	switch {
+	case m.Role == "assistant":
+		role = "assistant"
+		if m.Content != "" {
+			blocks = append(blocks, anthropicBlock{Type: "text", Text: m.Content})
+		}
+		for _, tc := range m.ToolCalls {
+			input := json.RawMessage(tc.Arguments)
+			if !json.Valid(input) {
+				input = json.RawMessage(`{}`)
+			}
+			blocks = append(blocks, anthropicBlock{Type: "tool_use", ID: tc.ID, Name: tc.Name, Input: input})
+		}
+	case m.Role == "tool" && len(m.ToolResults) > 0:
+		for _, r := range m.ToolResults {
+			blocks = append(blocks, anthropicBlock{
+				Type: "tool_result", ToolUseID: r.ToolCallID, Content: r.Content, IsError: r.IsError,
+			})
+		}
+	case m.Content != "":
+		// "user" and bare "tool" turns carry plain text; empty text blocks are rejected
+		blocks = append(blocks, anthropicBlock{Type: "text", Text: m.Content})
+	}
+
+	if len(blocks) == 0 {
+		return msgs
+	}
+	if n := len(msgs); n > 0 && msgs[n-1].Role == role {
+		msgs[n-1].Content = append(msgs[n-1].Content, blocks...)
+		return msgs
+	}
+	return append(msgs, anthropicMessage{Role: role, Content: blocks})
+}
+
+// fromAnthropicResponse maps a Messages API response back to the engine-internal LLMResponse.
+//
+//nolint:gocritic // hugeParam requires pointer but anthropicResponse is heavily used as value in Layer 0
+func fromAnthropicResponse(r anthropicResponse) LLMResponse {
+	prompt := r.Usage.InputTokens + r.Usage.CacheCreationInputTokens + r.Usage.CacheReadInputTokens
+	res := LLMResponse{
+		StopReason: r.StopReason,
+		TokenUsage: TokenUsage{
+			PromptTokens:     prompt,
+			CompletionTokens: r.Usage.OutputTokens,
+			TotalTokens:      prompt + r.Usage.OutputTokens,
+		},
+	}
+
+	var text strings.Builder
+	for _, b := range r.Content {
+		switch b.Type {
+		case "text":
+			text.WriteString(b.Text)
+		case "tool_use":
+			args := string(b.Input)
+			if args == "" {
+				args = "{}"
+			}
+			res.ToolCalls = append(res.ToolCalls, ToolCall{ID: b.ID, Name: b.Name, Arguments: args})
+		}
+	}
+	res.Content = text.String()
+	return res
+}
+
+// toAnthropicTool converts an engine-internal ToolManifest to the Messages API format.
+//
+//nolint:gocritic // hugeParam requires pointer but ToolManifest is heavily used as value in Layer 0
+func toAnthropicTool(t ToolManifest) anthropicTool {
+	schema := t.Parameters
+	if len(schema) == 0 || !json.Valid(schema) {
+		schema = json.RawMessage(`{"type":"object","properties":{}}`)
+	}
+	return anthropicTool{Name: t.Name, Description: t.Description, InputSchema: schema}
+}
@@ -0,0 +1,164 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// makeAnthropicServer serves body with the given status and extra headers,
+// handing each decoded request to inspect, if non-nil.
+func makeAnthropicServer(t *testing.T, body string, statusCode int, headers map[string]string, inspect func(*http.Request, anthropicRequest)) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		var req anthropicRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if inspect != nil {
+			inspect(r, req)
+		}
+		for k, v := range headers {
+			w.Header().Set(k, v)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+func TestAnthropicAdapter_textResponseUsageAndStopReason(t *testing.T) {
+	srv := makeAnthropicServer(t, `{"id":"msg_1","type":"message","role":"assistant","model":"claude-x",
+		"content":[{"type":"text","text":"The answer "},{"type":"text","text":"is 42."}],
+		"stop_reason":"end_turn","usage":{"input_tokens":10,"output_tokens":20,"cache_read_input_tokens":5}}`,
+		http.StatusOK, nil, func(r *http.Request, req anthropicRequest) {
+			if r.Header.Get("X-Api-Key") != "test-key" || r.Header.Get("Anthropic-Version") != anthropicAPIVersion {
+				t.Errorf("missing auth or version headers: %v", r.Header)
+			}
+			if req.System != "You are helpful." || req.MaxTokens != anthropicDefaultMaxTokens {
+				t.Errorf("unexpected system %q / max_tokens %d", req.System, req.MaxTokens)
+			}
+			if len(req.Messages) != 1 || req.Messages[0].Role != "user" {
+				t.Errorf("system turn must not appear in messages: %+v", req.Messages)
+			}
+		})
+	defer srv.Close()
+
+	out, err := newTestAnthropicAdapter("claude-x", srv.URL).Generate(context.Background(), "You are helpful.", "What is the answer?")
+	if err != nil || out != "The answer is 42." {
+		t.Fatalf("Generate: %q, %v", out, err)
+	}
+
+	res, err := newTestAnthropicAdapter("claude-x", srv.URL).GenerateWithTools(context.Background(),
+		[]Message{{Role: "system", Content: "You are helpful."}, {Role: "user", Content: "q"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StopReason != "end_turn" {
+		t.Errorf("want stop_reason end_turn, got %q", res.StopReason)
+	}
+	if res.TokenUsage != (TokenUsage{PromptTokens: 15, CompletionTokens: 20, TotalTokens: 35}) {
+		t.Errorf("unexpected usage %+v", res.TokenUsage)
+	}
+}
+
+func TestAnthropicAdapter_toolUseRoundTrip(t *testing.T) {
+	var sent anthropicRequest
+	srv := makeAnthropicServer(t, `{"content":[{"type":"text","text":"Checking."},
+		{"type":"tool_use","id":"toolu_01","name":"sys_info","input":{"verbose":true}}],"stop_reason":"tool_use","usage":{}}`,
+		http.StatusOK, nil, func(_ *http.Request, req anthropicRequest) { sent = req })
+	defer srv.Close()
+
+	adapter := newTestAnthropicAdapter("claude-x", srv.URL)
+	res, err := adapter.GenerateWithTools(context.Background(), []Message{
+		{Role: "user", Content: "info"},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "toolu_00", Name: "sys_info", Arguments: `{}`}}},
+		{Role: "tool", ToolResults: []ToolResultMessage{{ToolCallID: "toolu_00", Content: "denied", IsError: true}}},
+		{Role: "user", Content: "try again"},
+	}, []ToolManifest{{Name: "sys_info", Description: "Returns current system metrics."}})
+	if err != nil {
+		t.Fatalf("GenerateWithTools: %v", err)
+	}
+	want := ToolCall{ID: "toolu_01", Name: "sys_info", Arguments: `{"verbose":true}`}
+	if res.Content != "Checking." || len(res.ToolCalls) != 1 || res.ToolCalls[0] != want || res.StopReason != "tool_use" {
+		t.Fatalf("unexpected response %+v", res)
+	}
+
+	if len(sent.Tools) != 1 || string(sent.Tools[0].InputSchema) != `{"type":"object","properties":{}}` {
+		t.Errorf("unexpected tools %+v", sent.Tools)
+	}
+	// The tool result and the follow-up user text merge into one user turn.
+	if len(sent.Messages) != 3 {
+		t.Fatalf("want alternating user/assistant/user turns, got %+v", sent.Messages)
+	}
+	use := sent.Messages[1].Content[0]
+	if use.Type != "tool_use" || use.ID != "toolu_00" || string(use.Input) != `{}` {
+		t.Errorf("unexpected tool_use block %+v", use)
+	}
+	result := sent.Messages[2].Content
+	if len(result) != 2 || result[0].Type != "tool_result" || result[0].ToolUseID != "toolu_00" || !result[0].IsError ||
+		result[1].Type != "text" {
+		t.Errorf("unexpected user turn %+v", result)
+	}
+}
+
+func TestAnthropicAdapter_rateLimitHeaders(t *testing.T) {
+	reset := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	srv := makeAnthropicServer(t, `{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`,
+		http.StatusTooManyRequests, map[string]string{
+			"Retry-After":                            "7",
+			"Anthropic-Ratelimit-Requests-Limit":     "50",
+			"Anthropic-Ratelimit-Requests-Remaining": "0",
+			"Anthropic-Ratelimit-Requests-Reset":     reset.Format(time.RFC3339),
+			"Anthropic-Ratelimit-Tokens-Limit":       "40000",
+			"Anthropic-Ratelimit-Tokens-Remaining":   "1200",
+		}, nil)
+	defer srv.Close()
+
+	adapter := newTestAnthropicAdapter("claude-x", srv.URL)
+	if _, ok := adapter.RateLimit(); ok {
+		t.Fatal("no rate limit expected before the first response")
+	}
+	_, err := adapter.GenerateWithTools(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusTooManyRequests ||
+		!statusErr.Temporary() || statusErr.RetryAfter != 7*time.Second {
+		t.Fatalf("want temporary 429 with Retry-After 7s, got %#v", err)
+	}
+
+	rl, ok := adapter.RateLimit()
+	want := AnthropicRateLimit{RequestsLimit: 50, RequestsReset: reset, TokensLimit: 40000, TokensRemaining: 1200}
+	if !ok || rl != want {
+		t.Errorf("want %+v, got %+v (%v)", want, rl, ok)
+	}
+}
+
+func TestAnthropicAdapter_modelHint(t *testing.T) {
+	a := newTestAnthropicAdapter("claude-x", "http://localhost")
+	cases := map[string]string{
+		"":                               "claude-x",
+		"claude-y":                       "claude-y",
+		"anthropic/claude":               "claude",
+		"ollama/phi3":                    "claude-x",
+		"meta-llama/Llama-3-8B-Instruct": "meta-llama/Llama-3-8B-Instruct",
+	}
+	for hint, want := range cases {
+		ctx := context.Background()
+		if hint != "" {
+			ctx = WithModelHint(ctx, hint)
+		}
+		if got := a.modelFor(ctx); got != want {
+			t.Errorf("hint %q: want model %q, got %q", hint, want, got)
+		}
+	}
+	if got := a.Name(); got != "anthropic/claude-x" {
+		t.Errorf("want name=anthropic/claude-x, got %q", got)
+	}
+}
@@ -2,11 +2,12 @@ package llm
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"sort"
 )
 
-// CostRouter selects the cheapest healthy provider that meets a minimum capability threshold.
+// CostRouter selects the cheapest healthy provider that meets a minimum
+// capability threshold, falling back to degraded providers if none is healthy.
 type CostRouter struct {
 	providers     []Provider
 	minCapability int
@@ -20,19 +21,16 @@ func NewCostRouter(providers []Provider, minCapability int) *CostRouter {
 }
 
 func (r *CostRouter) Select(ctx context.Context, task string) (Provider, error) {
-	candidates := make([]Provider, 0, len(r.providers))
+	capable := make([]Provider, 0, len(r.providers))
 	for _, p := range r.providers {
-		if p.Status() != StatusHealthy {
-			continue
+		if p.Metadata().CapabilityRank >= r.minCapability {
+			capable = append(capable, p)
 		}
-		if p.Metadata().CapabilityRank < r.minCapability {
-			continue
-		}
-		candidates = append(candidates, p)
 	}
 
+	candidates := usable(ctx, capable)
 	if len(candidates) == 0 {
-		return nil, errors.New("no providers meet the health and capability requirements")
+		return nil, fmt.Errorf("%w: none meet capability rank %d", ErrNoHealthyProvider, r.minCapability)
 	}
 
 	// Sort candidates by cost (ascending)
@@ -2,7 +2,6 @@ package llm
 
 import (
 	"context"
-	"errors"
 	"sort"
 )
 
@@ -20,12 +19,11 @@ func NewFallbackRouter(providers []Provider) *FallbackRouter {
 	return &FallbackRouter{providers: providers}
 }
 
-// Select returns the first healthy provider according to priority.
+// Select returns the first healthy provider according to priority, or the
+// first degraded one if none is healthy.
 func (r *FallbackRouter) Select(ctx context.Context, task string) (Provider, error) {
-	for _, p := range r.providers {
-		if p.Status() == StatusHealthy {
-			return p, nil
-		}
+	if candidates := usable(ctx, r.providers); len(candidates) > 0 {
+		return candidates[0], nil
 	}
-	return nil, errors.New("no healthy LLM providers available")
+	return nil, ErrNoHealthyProvider
 }
@@ -16,9 +16,12 @@ func (m *MockProvider) Name() string            { return m.name }
 func (m *MockProvider) Status() Status          { return m.status }
 func (m *MockProvider) Priority() Priority      { return m.priority }
 func (m *MockProvider) Metadata() ModelMetadata { return m.metadata }
-func (m *MockProvider) Execute(ctx context.Context, task string) (string, error) {
+func (m *MockProvider) Generate(ctx context.Context, systemPrompt, userInput string) (string, error) {
 	return "mock-result", nil
 }
+func (m *MockProvider) GenerateWithTools(ctx context.Context, messages []Message, tools []ToolManifest) (LLMResponse, error) {
+	return LLMResponse{Content: "mock-result"}, nil
+}
 
 func TestFallbackRouter_Select(t *testing.T) {
 	t.Run("selects highest priority healthy provider", func(t *testing.T) {
@@ -0,0 +1,360 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by a HealthProvider whose circuit breaker is
+// rejecting requests.
+var ErrCircuitOpen = errors.New("llm provider circuit open")
+
+const (
+	defaultHealthWindow      = 20
+	defaultHealthMinSamples  = 5
+	defaultDegradedErrorRate = 0.25
+	defaultFailureThreshold  = 5
+	defaultOpenTimeout       = 30 * time.Second
+	defaultProbeInterval     = 15 * time.Second
+	defaultProbeTimeout      = 5 * time.Second
+)
+
+// BreakerState is the position of a HealthProvider's circuit breaker.
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"    // requests flow; outcomes are tracked
+	BreakerOpen     BreakerState = "open"      // requests fail fast with ErrCircuitOpen
+	BreakerHalfOpen BreakerState = "half_open" // a few trial requests decide recovery
+)
+
+// HealthConfig tunes a HealthProvider. Zero fields take the defaults noted.
+type HealthConfig struct {
+	// Window is how many recent calls the error rate covers (default 20).
+	Window int
+	// MinSamples is the fewest calls in the window before the error rate can
+	// mark the provider degraded (default 5).
+	MinSamples int
+	// DegradedErrorRate is the error rate at which the provider reports
+	// StatusDegraded (default 0.25).
+	DegradedErrorRate float64
+	// FailureThreshold is the number of consecutive failures that opens the
+	// circuit (default 5).
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before admitting trial
+	// requests (default 30s).
+	OpenTimeout time.Duration
+	// HalfOpenTrials is how many concurrent trial requests a half-open circuit
+	// admits (default 1).
+	HalfOpenTrials int
+	// Probe optionally checks the backend out of band, e.g. OllamaAdapter.Ping.
+	// A failed probe opens the circuit; a passing probe lets an open circuit
+	// try requests again without waiting out OpenTimeout. See RunProbes.
+	Probe func(ctx context.Context) error
+	// ProbeInterval is the period of RunProbes (default 15s).
+	ProbeInterval time.Duration
+}
+
+func (c *HealthConfig) normalize() {
+	if c.Window <= 0 {
+		c.Window = defaultHealthWindow
+	}
+	if c.MinSamples <= 0 {
+		c.MinSamples = defaultHealthMinSamples
+	}
+	if c.DegradedErrorRate <= 0 {
+		c.DegradedErrorRate = defaultDegradedErrorRate
+	}
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = defaultFailureThreshold
+	}
+	if c.OpenTimeout <= 0 {
+		c.OpenTimeout = defaultOpenTimeout
+	}
+	if c.HalfOpenTrials <= 0 {
+		c.HalfOpenTrials = 1
+	}
+	if c.ProbeInterval <= 0 {
+		c.ProbeInterval = defaultProbeInterval
+	}
+}
+
+// HealthSnapshot is the observed health of a provider.
+type HealthSnapshot struct {
+	Status              Status
+	Breaker             BreakerState
+	ErrorRate           float64 // over the last Samples calls
+	Samples             int
+	ConsecutiveFailures int
+	LastError           string
+	OpenedAt            time.Time // when the circuit last opened
+}
+
+// HealthProvider wraps a Provider and derives its Status from observed
+// outcomes instead of trusting the wrapped implementation. Failures are
+// errors other than the caller's cancellation or deadline and non-retryable
+// client errors (HTTP 4xx except 429); provider-side timeouts count.
+//
+// A closed circuit reports StatusDegraded once the recent error rate reaches
+// DegradedErrorRate, and opens after FailureThreshold consecutive failures.
+// An open circuit reports StatusOffline and fails fast until OpenTimeout
+// passes. It then turns half-open and reports StatusHealthy while it has a
+// free trial slot, so routers send it a trial request: success closes the
+// circuit, failure reopens it.
+type HealthProvider struct {
+	base Provider
+	cfg  HealthConfig
+	now  func() time.Time
+
+	mu          sync.Mutex
+	outcomes    []bool // ring of recent results, true for failure
+	next        int
+	failures    int // failures in outcomes
+	consecutive int
+	lastErr     error
+	state       BreakerState
+	openedAt    time.Time
+	trials      int    // in-flight half-open trials
+	generation  uint64 // bumped each time the circuit turns half-open
+}
+
+// NewHealthProvider wraps base with health tracking and a circuit breaker.
+func NewHealthProvider(base Provider, cfg HealthConfig) *HealthProvider {
+	cfg.normalize()
+	return &HealthProvider{
+		base:     base,
+		cfg:      cfg,
+		now:      time.Now,
+		outcomes: make([]bool, 0, cfg.Window),
+		state:    BreakerClosed,
+	}
+}
+
+func (h *HealthProvider) Name() string            { return h.base.Name() }
+func (h *HealthProvider) Priority() Priority      { return h.base.Priority() }
+func (h *HealthProvider) Metadata() ModelMetadata { return h.base.Metadata() }
+
+// Status reports the routing status derived from observed health; a base
+// provider that reports itself offline stays offline.
+func (h *HealthProvider) Status() Status {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.statusLocked()
+}
+
+// Health returns a snapshot of the tracked health.
+func (h *HealthProvider) Health() HealthSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	snap := HealthSnapshot{
+		Status:              h.statusLocked(),
+		Breaker:             h.state,
+		ErrorRate:           h.errorRateLocked(),
+		Samples:             len(h.outcomes),
+		ConsecutiveFailures: h.consecutive,
+		OpenedAt:            h.openedAt,
+	}
+	if h.lastErr != nil {
+		snap.LastError = h.lastErr.Error()
+	}
+	return snap
+}
+
+func (h *HealthProvider) Generate(ctx context.Context, systemPrompt, userInput string) (string, error) {
+	trial, err := h.admit()
+	if err != nil {
+		return "", err
+	}
+	out, err := h.base.Generate(ctx, systemPrompt, userInput)
+	h.record(ctx, trial, err)
+	return out, err
+}
+
+func (h *HealthProvider) GenerateWithTools(ctx context.Context, messages []Message, tools []ToolManifest) (LLMResponse, error) {
+	trial, err := h.admit()
+	if err != nil {
+		return LLMResponse{}, err
+	}
+	res, err := h.base.GenerateWithTools(ctx, messages, tools)
+	h.record(ctx, trial, err)
+	return res, err
+}
+
+func (h *HealthProvider) GenerateWithToolsStream(ctx context.Context, messages []Message, tools []ToolManifest, onChunk func(StreamChunk)) (LLMResponse, error) {
+	trial, err := h.admit()
+	if err != nil {
+		return LLMResponse{}, err
+	}
+	res, err := GenerateStream(ctx, h.base, messages, tools, onChunk)
+	h.record(ctx, trial, err)
+	return res, err
+}
+
+// Probe runs the configured probe once and applies its outcome. It is a
+// no-op without a probe.
+func (h *HealthProvider) Probe(ctx context.Context) error {
+	if h.cfg.Probe == nil {
+		return nil
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, defaultProbeTimeout)
+	defer cancel()
+	err := h.cfg.Probe(probeCtx)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	switch {
+	case err != nil && ctx.Err() != nil:
+		// cancelled by the caller; says nothing about the backend
+	case err != nil:
+		h.lastErr = err
+		h.openLocked()
+	case h.state == BreakerOpen:
+		h.halfOpenLocked()
+	}
+	return err
+}
+
+// RunProbes probes every ProbeInterval until ctx ends. Run it in its own
+// goroutine.
+func (h *HealthProvider) RunProbes(ctx context.Context) {
+	if h.cfg.Probe == nil {
+		return
+	}
+	ticker := time.NewTicker(h.cfg.ProbeInterval)
+	defer ticker.Stop()
+	for {
+		_ = h.Probe(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// admit lets a request through the breaker, reserving a trial slot when
+// half-open. trial identifies the half-open period the slot belongs to and is
+// zero for requests admitted while the circuit was closed.
+func (h *HealthProvider) admit() (trial uint64, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.advanceLocked()
+	switch h.state {
+	case BreakerOpen:
+		return 0, fmt.Errorf("%w: %s", ErrCircuitOpen, h.base.Name())
+	case BreakerHalfOpen:
+		if h.trials >= h.cfg.HalfOpenTrials {
+			return 0, fmt.Errorf("%w: %s awaiting trial result", ErrCircuitOpen, h.base.Name())
+		}
+		h.trials++
+		return h.generation, nil
+	}
+	return 0, nil
+}
+
+// record applies the outcome of an admitted request made under ctx. Only a
+// trial of the current half-open period can close or reopen the circuit;
+// other outcomes just feed the error rate.
+func (h *HealthProvider) record(ctx context.Context, trial uint64, err error) {
+	failed := countsAsFailure(ctx, err)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if trial != 0 && trial == h.generation && h.state == BreakerHalfOpen {
+		h.trials = max(h.trials-1, 0)
+		if err != nil && !failed {
+			return // inconclusive trial
+		}
+		if failed {
+			h.lastErr = err
+			h.openLocked()
+			return
+		}
+		h.state = BreakerClosed
+		h.outcomes, h.next, h.failures = h.outcomes[:0], 0, 0
+	}
+	if err != nil && !failed {
+		return
+	}
+
+	if len(h.outcomes) < h.cfg.Window {
+		h.outcomes = append(h.outcomes, failed)
+	} else {
+		if h.outcomes[h.next] {
+			h.failures--
+		}
+		h.outcomes[h.next] = failed
+		h.next = (h.next + 1) % h.cfg.Window
+	}
+	if !failed {
+		h.consecutive = 0
+		return
+	}
+	h.failures++
+	h.consecutive++
+	h.lastErr = err
+	if h.state == BreakerClosed && h.consecutive >= h.cfg.FailureThreshold {
+		h.openLocked()
+	}
+}
+
+func (h *HealthProvider) openLocked() {
+	h.state, h.openedAt, h.trials = BreakerOpen, h.now(), 0
+}
+
+// advanceLocked moves an open circuit to half-open once OpenTimeout has passed.
+func (h *HealthProvider) advanceLocked() {
+	if h.state == BreakerOpen && h.now().Sub(h.openedAt) >= h.cfg.OpenTimeout {
+		h.halfOpenLocked()
+	}
+}
+
+func (h *HealthProvider) halfOpenLocked() {
+	h.state, h.trials = BreakerHalfOpen, 0
+	h.generation++
+}
+
+func (h *HealthProvider) statusLocked() Status {
+	base := h.base.Status()
+	if base == StatusOffline {
+		return StatusOffline
+	}
+	h.advanceLocked()
+	switch {
+	case h.state == BreakerOpen:
+		return StatusOffline
+	case h.state == BreakerHalfOpen && h.trials >= h.cfg.HalfOpenTrials:
+		return StatusOffline
+	case h.state == BreakerHalfOpen:
+		return StatusHealthy
+	case len(h.outcomes) >= h.cfg.MinSamples && h.errorRateLocked() >= h.cfg.DegradedErrorRate:
+		return StatusDegraded
+	default:
+		return base
+	}
+}
+
+func (h *HealthProvider) errorRateLocked() float64 {
+	if len(h.outcomes) == 0 {
+		return 0
+	}
+	return float64(h.failures) / float64(len(h.outcomes))
+}
+
+// countsAsFailure reports whether err, returned by a call made under ctx,
+// reflects on the provider's health. Timeouts, transport and server errors do;
+// the caller's own cancellation or deadline and rejected requests (4xx other
+// than 429) do not.
+func countsAsFailure(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Temporary()
+	}
+	return true
+}
@@ -0,0 +1,184 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// switchAdapter fails with err while it is set and counts calls.
+type switchAdapter struct {
+	stubAdapter
+	calls int
+}
+
+func (a *switchAdapter) GenerateWithTools(ctx context.Context, m []Message, tools []ToolManifest) (LLMResponse, error) {
+	a.calls++
+	return a.stubAdapter.GenerateWithTools(ctx, m, tools)
+}
+
+// newTestHealthProvider wraps a switchAdapter and drives the breaker from a fake clock.
+func newTestHealthProvider(cfg HealthConfig) (*HealthProvider, *switchAdapter, *time.Time) {
+	adapter := &switchAdapter{stubAdapter: stubAdapter{name: "flaky", res: LLMResponse{Content: "ok"}}}
+	h := NewHealthProvider(NewProvider(adapter, 1, ModelMetadata{}), cfg)
+	clock := time.Unix(0, 0)
+	h.now = func() time.Time { return clock }
+	return h, adapter, &clock
+}
+
+func call(h *HealthProvider) error {
+	_, err := h.GenerateWithTools(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil)
+	return err
+}
+
+func TestHealthProvider_breakerOpensAndRecoversThroughTrial(t *testing.T) {
+	h, adapter, clock := newTestHealthProvider(HealthConfig{FailureThreshold: 3, OpenTimeout: time.Minute})
+	adapter.err = &StatusError{Provider: "flaky", StatusCode: http.StatusBadGateway}
+
+	for range 3 {
+		_ = call(h)
+	}
+	if snap := h.Health(); snap.Breaker != BreakerOpen || snap.Status != StatusOffline || snap.ConsecutiveFailures != 3 {
+		t.Fatalf("want open circuit after 3 failures, got %+v", snap)
+	}
+	if err := call(h); !errors.Is(err, ErrCircuitOpen) || adapter.calls != 3 {
+		t.Fatalf("open circuit must fail fast, got %v after %d calls", err, adapter.calls)
+	}
+
+	*clock = clock.Add(time.Minute)
+	if h.Status() != StatusHealthy {
+		t.Fatalf("half-open circuit should invite a trial, got %s", h.Status())
+	}
+	if err := call(h); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("trial should reach the backend, got %v", err)
+	}
+	if snap := h.Health(); snap.Breaker != BreakerOpen || !snap.OpenedAt.Equal(*clock) {
+		t.Fatalf("failed trial must reopen the circuit, got %+v", snap)
+	}
+
+	*clock = clock.Add(time.Minute)
+	adapter.err = nil
+	if err := call(h); err != nil {
+		t.Fatalf("trial: %v", err)
+	}
+	if snap := h.Health(); snap.Breaker != BreakerClosed || snap.Status != StatusHealthy {
+		t.Fatalf("successful trial must close the circuit, got %+v", snap)
+	}
+}
+
+func TestHealthProvider_halfOpenAdmitsLimitedTrials(t *testing.T) {
+	h, _, clock := newTestHealthProvider(HealthConfig{FailureThreshold: 1})
+	h.mu.Lock()
+	h.openLocked()
+	h.mu.Unlock()
+	*clock = clock.Add(defaultOpenTimeout)
+
+	if _, err := h.admit(); err != nil {
+		t.Fatalf("first trial: %v", err)
+	}
+	if h.Status() != StatusOffline {
+		t.Errorf("no trial slots left; want offline, got %s", h.Status())
+	}
+	if _, err := h.admit(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("second concurrent trial must be rejected, got %v", err)
+	}
+}
+
+func TestHealthProvider_onlyTrialsDecideHalfOpenCircuit(t *testing.T) {
+	h, _, clock := newTestHealthProvider(HealthConfig{FailureThreshold: 1})
+	ctx := context.Background()
+	normal, _ := h.admit() // admitted while closed, completes later
+	h.mu.Lock()
+	h.openLocked()
+	h.mu.Unlock()
+	*clock = clock.Add(defaultOpenTimeout)
+	trial, err := h.admit()
+	if err != nil || trial == normal {
+		t.Fatalf("want a tagged trial, got %d (%v)", trial, err)
+	}
+
+	h.record(ctx, normal, nil)
+	if snap := h.Health(); snap.Breaker != BreakerHalfOpen || snap.Status != StatusOffline {
+		t.Fatalf("a non-trial success must neither close the circuit nor free the trial slot, got %+v", snap)
+	}
+	h.record(ctx, trial, nil)
+	if snap := h.Health(); snap.Breaker != BreakerClosed {
+		t.Fatalf("the trial's success must close the circuit, got %+v", snap)
+	}
+}
+
+func TestHealthProvider_errorRateDegradesAndRoutersAvoidIt(t *testing.T) {
+	h, adapter, _ := newTestHealthProvider(HealthConfig{MinSamples: 4, DegradedErrorRate: 0.5, FailureThreshold: 10})
+	backup := &MockProvider{name: "backup", status: StatusHealthy, priority: 2}
+	router := NewFallbackRouter([]Provider{h, backup})
+
+	for i := range 4 {
+		adapter.err = nil
+		if i%2 == 0 {
+			adapter.err = context.DeadlineExceeded
+		}
+		_ = call(h)
+	}
+	if snap := h.Health(); snap.Status != StatusDegraded || snap.ErrorRate != 0.5 || snap.Breaker != BreakerClosed {
+		t.Fatalf("want degraded at 50%% errors, got %+v", snap)
+	}
+	if p, _ := router.Select(context.Background(), "task"); p.Name() != "backup" {
+		t.Errorf("router should prefer the healthy backup, got %s", p.Name())
+	}
+
+	backup.status = StatusOffline
+	if p, err := router.Select(context.Background(), "task"); err != nil || p.Name() != "flaky" {
+		t.Errorf("a degraded provider is the last resort, got %v (%v)", p, err)
+	}
+}
+
+func TestHealthProvider_clientErrorsDoNotCount(t *testing.T) {
+	h, adapter, _ := newTestHealthProvider(HealthConfig{FailureThreshold: 1})
+	for _, err := range []error{&StatusError{StatusCode: http.StatusBadRequest}, context.Canceled} {
+		adapter.err = err
+		_ = call(h)
+	}
+	if snap := h.Health(); snap.Breaker != BreakerClosed || snap.Samples != 0 {
+		t.Fatalf("client errors must not affect health, got %+v", snap)
+	}
+}
+
+func TestHealthProvider_callerDeadlineDoesNotCount(t *testing.T) {
+	h, adapter, _ := newTestHealthProvider(HealthConfig{FailureThreshold: 1})
+	adapter.err = context.DeadlineExceeded
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	_, _ = h.GenerateWithTools(ctx, []Message{{Role: "user", Content: "hi"}}, nil)
+	if snap := h.Health(); snap.Breaker != BreakerClosed || snap.Samples != 0 {
+		t.Fatalf("the caller's own deadline must not affect health, got %+v", snap)
+	}
+}
+
+func TestHealthProvider_probes(t *testing.T) {
+	healthy := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/tags" {
+			t.Errorf("unexpected probe path %s", r.URL.Path)
+		}
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"models":[]}`))
+	}))
+	defer srv.Close()
+
+	ollama := newTestOllamaAdapter("llama3.2", srv.URL)
+	h := NewHealthProvider(NewProvider(ollama, 1, ModelMetadata{}), HealthConfig{Probe: ollama.Ping})
+
+	if err := h.Probe(context.Background()); err == nil || h.Health().Breaker != BreakerOpen {
+		t.Fatalf("failed probe must open the circuit, got %v / %+v", err, h.Health())
+	}
+	healthy = true
+	if err := h.Probe(context.Background()); err != nil || h.Health().Breaker != BreakerHalfOpen {
+		t.Fatalf("passing probe must allow a trial, got %v / %+v", err, h.Health())
+	}
+}
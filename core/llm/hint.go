@@ -0,0 +1,59 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+type (
+	modelHintKey      struct{}
+	responseFormatKey struct{}
+)
+
+// WithModelHint returns a context carrying a preferred model or provider name.
+// Adapters that serve several models honour it; others ignore it.
+func WithModelHint(ctx context.Context, model string) context.Context {
+	return context.WithValue(ctx, modelHintKey{}, model)
+}
+
+// ModelHintFromContext returns the model hint set by WithModelHint, if any.
+func ModelHintFromContext(ctx context.Context) string {
+	hint, _ := ctx.Value(modelHintKey{}).(string)
+	return hint
+}
+
+// providerPrefixes are the provider names a model hint may be addressed to,
+// as in "ollama/phi3". Any other slash belongs to the model ID itself
+// (e.g. "meta-llama/Llama-3-8B-Instruct" or "hf.co/org/model").
+var providerPrefixes = []string{"ollama", "openai", "anthropic"}
+
+// hintedModel resolves hint for the named provider. It strips the provider's
+// own prefix, returns "" for hints addressed to another known provider, and
+// otherwise passes the hint through unchanged.
+func hintedModel(hint, provider string) string {
+	for _, p := range providerPrefixes {
+		model, ok := strings.CutPrefix(hint, p+"/")
+		if !ok {
+			continue
+		}
+		if p != provider {
+			return ""
+		}
+		return model
+	}
+	return hint
+}
+
+// WithResponseFormat returns a context asking the adapter to constrain the
+// reply content to the given JSON Schema. Adapters with native structured
+// output (such as Ollama's "format" field) honour it; others ignore it.
+func WithResponseFormat(ctx context.Context, schema json.RawMessage) context.Context {
+	return context.WithValue(ctx, responseFormatKey{}, schema)
+}
+
+// ResponseFormatFromContext returns the schema set by WithResponseFormat, if any.
+func ResponseFormatFromContext(ctx context.Context) json.RawMessage {
+	schema, _ := ctx.Value(responseFormatKey{}).(json.RawMessage)
+	return schema
+}
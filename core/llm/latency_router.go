@@ -2,11 +2,11 @@ package llm
 
 import (
 	"context"
-	"errors"
 	"sort"
 )
 
-// LatencyRouter selects the provider with the lowest expected latency.
+// LatencyRouter selects the provider with the lowest expected latency,
+// preferring healthy providers over degraded ones.
 type LatencyRouter struct {
 	providers []Provider
 }
@@ -16,15 +16,9 @@ func NewLatencyRouter(providers []Provider) *LatencyRouter {
 }
 
 func (r *LatencyRouter) Select(ctx context.Context, task string) (Provider, error) {
-	var healthy []Provider
-	for _, p := range r.providers {
-		if p.Status() == StatusHealthy {
-			healthy = append(healthy, p)
-		}
-	}
-
+	healthy := usable(ctx, r.providers)
 	if len(healthy) == 0 {
-		return nil, errors.New("no healthy LLM providers available")
+		return nil, ErrNoHealthyProvider
 	}
 
 	// Sort by latency (ascending)
@@ -1,15 +1,21 @@
 package llm
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
+// ollamaMaxStreamLine bounds a single NDJSON line in a streamed response.
+const ollamaMaxStreamLine = 1 << 20
+
 // OllamaAdapter implements LLMAdapter against a locally running Ollama instance.
 // It uses the /api/chat endpoint (Ollama v0.3+) with native tool-call support,
 // enabling true multi-iteration ReAct loops without any LangChain dependency.
@@ -19,34 +25,41 @@ type OllamaAdapter struct {
 	model   string
 	baseURL string // overridable for tests via newTestOllamaAdapter
 	http    *http.Client
+	stream  *http.Client // http without a total timeout, for streamed chats
 }
 
 // NewOllamaAdapter constructs an adapter pointing at the default local Ollama server.
 func NewOllamaAdapter(model string) *OllamaAdapter {
-	return &OllamaAdapter{
+	a := &OllamaAdapter{
 		model:   model,
 		baseURL: "http://localhost:11434",
 		http:    &http.Client{Timeout: 120 * time.Second},
 	}
+	a.stream = streamingClient(a.http)
+	return a
 }
 
 // NewOllamaAdapterWithURL constructs an adapter pointing at the provided base URL.
 // Intended for integration tests and non-default Ollama deployments.
 func NewOllamaAdapterWithURL(model, baseURL string) *OllamaAdapter {
-	return &OllamaAdapter{
+	a := &OllamaAdapter{
 		model:   model,
 		baseURL: baseURL,
 		http:    &http.Client{Timeout: 30 * time.Second},
 	}
+	a.stream = streamingClient(a.http)
+	return a
 }
 
 // newTestOllamaAdapter constructs an adapter pointing at a test server (e.g. httptest.Server).
 func newTestOllamaAdapter(model, baseURL string) *OllamaAdapter {
-	return &OllamaAdapter{
+	a := &OllamaAdapter{
 		model:   model,
 		baseURL: baseURL,
 		http:    &http.Client{Timeout: 5 * time.Second},
 	}
+	a.stream = streamingClient(a.http)
+	return a
 }
 
 // Name returns the adapter identifier used in routing tables.
@@ -69,9 +82,91 @@ func (a *OllamaAdapter) Generate(ctx context.Context, systemPrompt, userInput st
 // When the model decides to invoke a tool it returns a non-empty ToolCalls slice;
 // the Engine's ReAct loop appends the tool result and calls again.
 func (a *OllamaAdapter) GenerateWithTools(ctx context.Context, messages []Message, tools []ToolManifest) (LLMResponse, error) {
+	resp, err := a.postChat(ctx, a.buildChatRequest(ctx, messages, tools, false))
+	if err != nil {
+		return LLMResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return LLMResponse{}, fmt.Errorf("ollama: read response: %w", err)
+	}
+
+	var ollamaResp ollamaChatResponse
+	if err := json.Unmarshal(raw, &ollamaResp); err != nil {
+		return LLMResponse{}, fmt.Errorf("ollama: decode response: %w", err)
+	}
+
+	return a.fromOllamaResponse(ollamaResp), nil
+}
+
+// GenerateWithToolsStream is the streaming variant of GenerateWithTools. It sets
+// stream:true and consumes Ollama's NDJSON response line by line, forwarding each
+// content fragment to onChunk. Tool calls and token counts are accumulated and
+// returned in the assembled LLMResponse once the final (done:true) line arrives.
+func (a *OllamaAdapter) GenerateWithToolsStream(ctx context.Context, messages []Message, tools []ToolManifest, onChunk func(StreamChunk)) (LLMResponse, error) {
+	resp, err := a.postChat(ctx, a.buildChatRequest(ctx, messages, tools, true))
+	if err != nil {
+		return LLMResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	var (
+		assembled ollamaChatResponse
+		content   strings.Builder
+	)
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), ollamaMaxStreamLine)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk ollamaChatResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return LLMResponse{}, fmt.Errorf("ollama: decode stream chunk: %w", err)
+		}
+		if chunk.Error != "" {
+			return LLMResponse{}, fmt.Errorf("ollama: stream error: %s", chunk.Error)
+		}
+
+		if chunk.Message.Content != "" {
+			content.WriteString(chunk.Message.Content)
+			if onChunk != nil {
+				onChunk(StreamChunk{Content: chunk.Message.Content})
+			}
+		}
+		assembled.Message.ToolCalls = append(assembled.Message.ToolCalls, chunk.Message.ToolCalls...)
+
+		if chunk.Done {
+			assembled.Model = chunk.Model
+			assembled.Done = true
+			assembled.PromptEval = chunk.PromptEval
+			assembled.EvalCount = chunk.EvalCount
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return LLMResponse{}, fmt.Errorf("ollama: read stream: %w", err)
+	}
+	if !assembled.Done {
+		return LLMResponse{}, errors.New("ollama: stream ended before done marker")
+	}
+
+	assembled.Message.Role = "assistant"
+	assembled.Message.Content = content.String()
+	return a.fromOllamaResponse(assembled), nil
+}
+
+// buildChatRequest converts engine-internal messages and manifests into the
+// /api/chat request body.
+func (a *OllamaAdapter) buildChatRequest(ctx context.Context, messages []Message, tools []ToolManifest, stream bool) ollamaChatRequest {
 	reqBody := ollamaChatRequest{
-		Model:  a.model,
-		Stream: false,
+		Model:  a.modelFor(ctx),
+		Format: ResponseFormatFromContext(ctx),
+		Stream: stream,
 	}
 
 	for _, m := range messages {
@@ -81,39 +176,70 @@ func (a *OllamaAdapter) GenerateWithTools(ctx context.Context, messages []Messag
 	for _, t := range tools {
 		reqBody.Tools = append(reqBody.Tools, toOllamaTool(t))
 	}
+	return reqBody
+}
 
+// modelFor resolves the model to request. A context hint of the form "name" or
+// "ollama/name" overrides the configured model; hints addressed to other
+// providers are ignored.
+func (a *OllamaAdapter) modelFor(ctx context.Context) string {
+	if model := hintedModel(ModelHintFromContext(ctx), "ollama"); model != "" {
+		return model
+	}
+	return a.model
+}
+
+// postChat issues POST /api/chat and returns the open response on HTTP 200.
+// The caller owns resp.Body. Non-200 responses are drained and returned as errors.
+func (a *OllamaAdapter) postChat(ctx context.Context, reqBody ollamaChatRequest) (*http.Response, error) {
 	payload, err := json.Marshal(reqBody)
 	if err != nil {
-		return LLMResponse{}, fmt.Errorf("ollama: marshal request: %w", err)
+		return nil, fmt.Errorf("ollama: marshal request: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL+"/api/chat", bytes.NewReader(payload))
 	if err != nil {
-		return LLMResponse{}, fmt.Errorf("ollama: build request: %w", err)
+		return nil, fmt.Errorf("ollama: build request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := a.http.Do(req)
-	if err != nil {
-		return LLMResponse{}, fmt.Errorf("ollama: POST /api/chat: %w", err)
+	client := a.http
+	if reqBody.Stream {
+		client = a.stream
 	}
-	defer resp.Body.Close()
-
-	raw, err := io.ReadAll(resp.Body)
+	resp, err := client.Do(req)
 	if err != nil {
-		return LLMResponse{}, fmt.Errorf("ollama: read response: %w", err)
+		return nil, fmt.Errorf("ollama: POST /api/chat: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return LLMResponse{}, fmt.Errorf("ollama: HTTP %d: %s", resp.StatusCode, raw)
+		defer resp.Body.Close()
+		raw, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return nil, fmt.Errorf("ollama: read response: %w", readErr)
+		}
+		return nil, &StatusError{Provider: "ollama", StatusCode: resp.StatusCode, Body: string(raw)}
 	}
+	return resp, nil
+}
 
-	var ollamaResp ollamaChatResponse
-	if err := json.Unmarshal(raw, &ollamaResp); err != nil {
-		return LLMResponse{}, fmt.Errorf("ollama: decode response: %w", err)
+// Ping checks that the server is reachable by listing local models
+// (GET /api/tags). It suits HealthConfig.Probe.
+func (a *OllamaAdapter) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.baseURL+"/api/tags", http.NoBody)
+	if err != nil {
+		return fmt.Errorf("ollama: build request: %w", err)
 	}
-
-	return a.fromOllamaResponse(ollamaResp), nil
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("ollama: GET /api/tags: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{Provider: "ollama", StatusCode: resp.StatusCode}
+	}
+	return nil
 }
 
 // ---- Ollama wire types -------------------------------------------------------
@@ -123,6 +249,7 @@ type ollamaChatRequest struct {
 	Model    string          `json:"model"`
 	Messages []ollamaMessage `json:"messages"`
 	Tools    []ollamaTool    `json:"tools,omitempty"`
+	Format   json.RawMessage `json:"format,omitempty"` // JSON Schema for structured output
 	Stream   bool            `json:"stream"`
 }
 
@@ -162,6 +289,7 @@ type ollamaChatResponse struct {
 	Done       bool          `json:"done"`
 	PromptEval int           `json:"prompt_eval_count"`
 	EvalCount  int           `json:"eval_count"`
+	Error      string        `json:"error,omitempty"` // set on in-stream failures
 }
 
 // ---- conversion helpers -----------------------------------------------------
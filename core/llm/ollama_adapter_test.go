@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 // ollamaTextResponse builds a canned Ollama /api/chat response with plain text content.
@@ -246,3 +248,159 @@ func TestOllamaAdapter_contextCancellation(t *testing.T) {
 		t.Fatal("expected error on cancelled context, got nil")
 	}
 }
+
+// ---- GenerateWithToolsStream tests ------------------------------------------
+
+// makeOllamaStreamServer serves the given NDJSON lines, flushing after each one.
+func makeOllamaStreamServer(t *testing.T, lines []string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !req.Stream {
+			t.Errorf("expected stream:true request, got stream=%v err=%v", req.Stream, err)
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		flusher, _ := w.(http.Flusher)
+		for _, l := range lines {
+			_, _ = w.Write([]byte(l + "\n"))
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}))
+}
+
+func TestOllamaAdapter_stream_assemblesChunks(t *testing.T) {
+	srv := makeOllamaStreamServer(t, []string{
+		`{"model":"llama3.2","message":{"role":"assistant","content":"The "},"done":false}`,
+		`{"model":"llama3.2","message":{"role":"assistant","content":"answer"},"done":false}`,
+		`{"model":"llama3.2","message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":7,"eval_count":3}`,
+	})
+	defer srv.Close()
+
+	adapter := newTestOllamaAdapter("llama3.2", srv.URL)
+	var chunks []string
+	res, err := adapter.GenerateWithToolsStream(context.Background(), []Message{
+		{Role: "user", Content: "question"},
+	}, nil, func(c StreamChunk) { chunks = append(chunks, c.Content) })
+	if err != nil {
+		t.Fatalf("GenerateWithToolsStream: %v", err)
+	}
+	if len(chunks) != 2 || chunks[0] != "The " || chunks[1] != "answer" {
+		t.Errorf("unexpected chunks: %q", chunks)
+	}
+	if res.Content != "The answer" {
+		t.Errorf("want assembled content=%q, got %q", "The answer", res.Content)
+	}
+	if res.TokenUsage.TotalTokens != 10 {
+		t.Errorf("want 10 total tokens, got %d", res.TokenUsage.TotalTokens)
+	}
+}
+
+func TestOllamaAdapter_stream_outlivesClientTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		flusher, _ := w.(http.Flusher)
+		_, _ = w.Write([]byte(`{"message":{"role":"assistant","content":"slow "},"done":false}` + "\n"))
+		flusher.Flush()
+		time.Sleep(300 * time.Millisecond) // a long generation
+		_, _ = w.Write([]byte(`{"message":{"role":"assistant","content":"answer"},"done":true}` + "\n"))
+	}))
+	defer srv.Close()
+
+	adapter := newTestOllamaAdapter("llama3.2", srv.URL)
+	adapter.http.Timeout = 100 * time.Millisecond
+	adapter.stream = streamingClient(adapter.http)
+	res, err := adapter.GenerateWithToolsStream(context.Background(), []Message{{Role: "user", Content: "q"}}, nil, nil)
+	if err != nil || res.Content != "slow answer" {
+		t.Fatalf("the client timeout must not cut a stream off: %q (%v)", res.Content, err)
+	}
+}
+
+func TestOllamaAdapter_stream_toolCalls(t *testing.T) {
+	srv := makeOllamaStreamServer(t, []string{
+		`{"model":"llama3.2","message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"sys_info","arguments":{}}}]},"done":false}`,
+		`{"model":"llama3.2","message":{"role":"assistant","content":""},"done":true}`,
+	})
+	defer srv.Close()
+
+	adapter := newTestOllamaAdapter("llama3.2", srv.URL)
+	res, err := adapter.GenerateWithToolsStream(context.Background(), []Message{
+		{Role: "user", Content: "info"},
+	}, []ToolManifest{{Name: "sys_info"}}, nil)
+	if err != nil {
+		t.Fatalf("GenerateWithToolsStream: %v", err)
+	}
+	if len(res.ToolCalls) != 1 || res.ToolCalls[0].Name != "sys_info" {
+		t.Fatalf("expected one sys_info tool call, got %+v", res.ToolCalls)
+	}
+}
+
+func TestOllamaAdapter_stream_errorLine(t *testing.T) {
+	srv := makeOllamaStreamServer(t, []string{
+		`{"model":"llama3.2","message":{"role":"assistant","content":"par"},"done":false}`,
+		`{"error":"model crashed"}`,
+	})
+	defer srv.Close()
+
+	adapter := newTestOllamaAdapter("llama3.2", srv.URL)
+	_, err := adapter.GenerateWithToolsStream(context.Background(), []Message{
+		{Role: "user", Content: "hi"},
+	}, nil, nil)
+	if err == nil {
+		t.Fatal("expected in-stream error, got nil")
+	}
+}
+
+func TestOllamaAdapter_stream_truncated(t *testing.T) {
+	srv := makeOllamaStreamServer(t, []string{
+		`{"model":"llama3.2","message":{"role":"assistant","content":"par"},"done":false}`,
+	})
+	defer srv.Close()
+
+	adapter := newTestOllamaAdapter("llama3.2", srv.URL)
+	_, err := adapter.GenerateWithToolsStream(context.Background(), []Message{
+		{Role: "user", Content: "hi"},
+	}, nil, nil)
+	if err == nil {
+		t.Fatal("expected error for stream without done marker, got nil")
+	}
+}
+
+func TestOllamaAdapter_modelHint(t *testing.T) {
+	a := newTestOllamaAdapter("llama3.2", "http://localhost")
+	cases := map[string]string{
+		"":              "llama3.2",
+		"qwen2.5":       "qwen2.5",
+		"ollama/phi3":   "phi3",
+		"openai/gpt-4o": "llama3.2",
+		"hf.co/bartowski/Llama-3.2-1B-Instruct-GGUF": "hf.co/bartowski/Llama-3.2-1B-Instruct-GGUF",
+	}
+	for hint, want := range cases {
+		ctx := context.Background()
+		if hint != "" {
+			ctx = WithModelHint(ctx, hint)
+		}
+		if got := a.modelFor(ctx); got != want {
+			t.Errorf("hint %q: want model %q, got %q", hint, want, got)
+		}
+	}
+}
+
+func TestOllamaAdapter_responseFormat(t *testing.T) {
+	a := newTestOllamaAdapter("llama3.2", "http://localhost")
+	msgs := []Message{{Role: "user", Content: "hi"}}
+
+	if req := a.buildChatRequest(context.Background(), msgs, nil, false); req.Format != nil {
+		t.Errorf("format must be omitted without a hint, got %s", req.Format)
+	}
+
+	schema := json.RawMessage(`{"type":"object"}`)
+	req := a.buildChatRequest(WithResponseFormat(context.Background(), schema), msgs, nil, false)
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(body), `"format":{"type":"object"}`) {
+		t.Errorf("request should carry the schema as format: %s", body)
+	}
+}
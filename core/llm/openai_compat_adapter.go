@@ -0,0 +1,476 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// openAIMaxStreamLine bounds a single server-sent event line in a streamed response.
+const openAIMaxStreamLine = 1 << 20
+
+// OpenAICompatAdapter implements LLMAdapter against any server speaking the
+// OpenAI Chat Completions protocol with tools, such as llama.cpp server, vLLM
+// and LM Studio. Tool call IDs issued by the model are preserved so results
+// can be matched back on the next turn.
+//
+// API reference: https://platform.openai.com/docs/api-reference/chat
+type OpenAICompatAdapter struct {
+	model   string
+	baseURL string // includes the version prefix, e.g. http://localhost:8000/v1
+	apiKey  string // sent as a bearer token when non-empty
+	http    *http.Client
+	stream  *http.Client // http without a total timeout, for streamed chats
+}
+
+// NewOpenAICompatAdapter constructs an adapter for the server at baseURL, which
+// must include the API version prefix (e.g. "http://localhost:8080/v1").
+// apiKey may be empty for local servers that do not authenticate.
+func NewOpenAICompatAdapter(baseURL, apiKey, model string) *OpenAICompatAdapter {
+	a := &OpenAICompatAdapter{
+		model:   model,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		http:    &http.Client{Timeout: 120 * time.Second},
+	}
+	a.stream = streamingClient(a.http)
+	return a
+}
+
+// newTestOpenAICompatAdapter constructs an adapter pointing at a test server (e.g. httptest.Server).
+func newTestOpenAICompatAdapter(model, baseURL, apiKey string) *OpenAICompatAdapter {
+	a := &OpenAICompatAdapter{
+		model:   model,
+		baseURL: baseURL,
+		apiKey:  apiKey,
+		http:    &http.Client{Timeout: 5 * time.Second},
+	}
+	a.stream = streamingClient(a.http)
+	return a
+}
+
+// Name returns the adapter identifier used in routing tables.
+func (a *OpenAICompatAdapter) Name() string { return "openai/" + a.model }
+
+// Generate is a convenience wrapper for single-turn text generation.
+func (a *OpenAICompatAdapter) Generate(ctx context.Context, systemPrompt, userInput string) (string, error) {
+	msgs := []Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userInput},
+	}
+	res, err := a.GenerateWithTools(ctx, msgs, nil)
+	if err != nil {
+		return "", err
+	}
+	return res.Content, nil
+}
+
+// GenerateWithTools sends a chat-completion request with tool definitions and
+// returns the first choice.
+func (a *OpenAICompatAdapter) GenerateWithTools(ctx context.Context, messages []Message, tools []ToolManifest) (LLMResponse, error) {
+	resp, err := a.postChat(ctx, a.buildChatRequest(ctx, messages, tools, false))
+	if err != nil {
+		return LLMResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return LLMResponse{}, fmt.Errorf("openai: read response: %w", err)
+	}
+
+	var chatResp openAIChatResponse
+	if err := json.Unmarshal(raw, &chatResp); err != nil {
+		return LLMResponse{}, fmt.Errorf("openai: decode response: %w", err)
+	}
+	if chatResp.Error != nil {
+		return LLMResponse{}, fmt.Errorf("openai: %s", chatResp.Error.Message)
+	}
+	if len(chatResp.Choices) == 0 {
+		return LLMResponse{}, errors.New("openai: response has no choices")
+	}
+	res := fromOpenAIMessage(chatResp.Choices[0].Message, chatResp.Usage)
+	res.StopReason = chatResp.Choices[0].FinishReason
+	return res, nil
+}
+
+// GenerateWithToolsStream is the streaming variant of GenerateWithTools. It
+// consumes the server-sent event stream, forwarding content deltas to onChunk
+// and reassembling tool calls from their indexed argument fragments. Usage is
+// taken from the final chunk when the server honours stream_options.
+func (a *OpenAICompatAdapter) GenerateWithToolsStream(ctx context.Context, messages []Message, tools []ToolManifest, onChunk func(StreamChunk)) (LLMResponse, error) {
+	resp, err := a.postChat(ctx, a.buildChatRequest(ctx, messages, tools, true))
+	if err != nil {
+		return LLMResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	var (
+		content strings.Builder
+		calls   = map[int]*openAIToolCall{}
+		usage   openAIUsage
+		stop    string
+		done    bool
+	)
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), openAIMaxStreamLine)
+	for scanner.Scan() {
+		data, ok := bytes.CutPrefix(bytes.TrimSpace(scanner.Bytes()), []byte("data:"))
+		if !ok {
+			continue // blank separators, comments and other SSE fields
+		}
+		data = bytes.TrimSpace(data)
+		if string(data) == "[DONE]" {
+			done = true
+			break
+		}
+
+		var chunk openAIChatResponse
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return LLMResponse{}, fmt.Errorf("openai: decode stream chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			return LLMResponse{}, fmt.Errorf("openai: stream error: %s", chunk.Error.Message)
+		}
+		if chunk.Usage.TotalTokens > 0 {
+			usage = chunk.Usage
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+
+		if reason := chunk.Choices[0].FinishReason; reason != "" {
+			stop = reason
+		}
+		delta := chunk.Choices[0].Delta
+		if delta.Content != nil && *delta.Content != "" {
+			content.WriteString(*delta.Content)
+			if onChunk != nil {
+				onChunk(StreamChunk{Content: *delta.Content})
+			}
+		}
+		mergeToolCallDeltas(calls, delta.ToolCalls)
+	}
+	if err := scanner.Err(); err != nil {
+		return LLMResponse{}, fmt.Errorf("openai: read stream: %w", err)
+	}
+	if !done {
+		return LLMResponse{}, errors.New("openai: stream ended before [DONE]")
+	}
+
+	text := content.String()
+	res := fromOpenAIMessage(openAIMessage{Content: &text, ToolCalls: sortedToolCalls(calls)}, usage)
+	res.StopReason = stop
+	return res, nil
+}
+
+// buildChatRequest converts engine-internal messages and manifests into the
+// /chat/completions request body.
+func (a *OpenAICompatAdapter) buildChatRequest(ctx context.Context, messages []Message, tools []ToolManifest, stream bool) openAIChatRequest {
+	reqBody := openAIChatRequest{
+		Model:  a.modelFor(ctx),
+		Stream: stream,
+	}
+	if stream {
+		reqBody.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+	}
+	if schema := ResponseFormatFromContext(ctx); len(schema) > 0 {
+		reqBody.ResponseFormat = &openAIResponseFormat{
+			Type:       "json_schema",
+			JSONSchema: &openAIJSONSchema{Name: "response", Schema: schema},
+		}
+	}
+
+	for _, m := range messages {
+		reqBody.Messages = append(reqBody.Messages, toOpenAIMessages(m)...)
+	}
+
+	for _, t := range tools {
+		reqBody.Tools = append(reqBody.Tools, toOpenAITool(t))
+	}
+	return reqBody
+}
+
+// modelFor resolves the model to request. A context hint of the form "name" or
+// "openai/name" overrides the configured model; hints addressed to other
+// providers are ignored.
+func (a *OpenAICompatAdapter) modelFor(ctx context.Context) string {
+	if model := hintedModel(ModelHintFromContext(ctx), "openai"); model != "" {
+		return model
+	}
+	return a.model
+}
+
+// postChat issues POST /chat/completions and returns the open response on HTTP 200.
+// The caller owns resp.Body. Non-200 responses are drained and returned as errors.
+func (a *OpenAICompatAdapter) postChat(ctx context.Context, reqBody openAIChatRequest) (*http.Response, error) {
+	payload, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("openai: marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("openai: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+a.apiKey)
+	}
+
+	client := a.http
+	if reqBody.Stream {
+		client = a.stream
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("openai: POST /chat/completions: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		raw, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return nil, fmt.Errorf("openai: read response: %w", readErr)
+		}
+		return nil, &StatusError{Provider: "openai", StatusCode: resp.StatusCode, Body: string(raw), RetryAfter: retryAfter(resp.Header)}
+	}
+	return resp, nil
+}
+
+// Ping checks that the server is reachable and accepts the credentials by
+// listing models (GET /models). It suits HealthConfig.Probe.
+func (a *OpenAICompatAdapter) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.baseURL+"/models", http.NoBody)
+	if err != nil {
+		return fmt.Errorf("openai: build request: %w", err)
+	}
+	if a.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+a.apiKey)
+	}
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("openai: GET /models: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{Provider: "openai", StatusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+// ---- OpenAI wire types -------------------------------------------------------
+
+// openAIChatRequest is the JSON body for POST /chat/completions.
+type openAIChatRequest struct {
+	Model          string                `json:"model"`
+	Messages       []openAIMessage       `json:"messages"`
+	Tools          []openAITool          `json:"tools,omitempty"`
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+	Stream         bool                  `json:"stream,omitempty"`
+	StreamOptions  *openAIStreamOptions  `json:"stream_options,omitempty"`
+}
+
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type openAIResponseFormat struct {
+	Type       string            `json:"type"` // "json_schema"
+	JSONSchema *openAIJSONSchema `json:"json_schema,omitempty"`
+}
+
+type openAIJSONSchema struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+}
+
+// openAIMessage is a single turn in the chat history. Content is a pointer
+// because assistant turns that only call tools carry a null content.
+type openAIMessage struct {
+	Role       string           `json:"role,omitempty"`
+	Content    *string          `json:"content"`
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+// openAIToolCall is a tool invocation; in stream deltas only Index identifies
+// the call after its first fragment.
+type openAIToolCall struct {
+	Index    int                `json:"index,omitempty"`
+	ID       string             `json:"id,omitempty"`
+	Type     string             `json:"type,omitempty"` // "function"
+	Function openAIToolCallFunc `json:"function"`
+}
+
+type openAIToolCallFunc struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"` // JSON-encoded object, as a string
+}
+
+// openAITool describes a tool in the OpenAI tool-definition format.
+type openAITool struct {
+	Type     string         `json:"type"` // always "function"
+	Function openAIFunction `json:"function"`
+}
+
+type openAIFunction struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+}
+
+// openAIChatResponse is both the complete response and a streamed chunk; the
+// latter carries Delta in place of Message.
+type openAIChatResponse struct {
+	Model   string         `json:"model"`
+	Choices []openAIChoice `json:"choices"`
+	Usage   openAIUsage    `json:"usage"`
+	Error   *openAIError   `json:"error,omitempty"`
+}
+
+type openAIChoice struct {
+	Message      openAIMessage `json:"message"`
+	Delta        openAIMessage `json:"delta"`
+	FinishReason string        `json:"finish_reason"`
+}
+
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+type openAIError struct {
+	Message string `json:"message"`
+}
+
+// ---- conversion helpers -----------------------------------------------------
+
+// toOpenAIMessages converts an engine-internal Message to the wire format.
+// Role "tool" is expanded to one message per result, each carrying the
+// tool_call_id of the call it answers.
+//
+//nolint:gocritic // hugeParam requires pointer but Message is heavily used as value in Layer 0
+func toOpenAIMessages(m Message) []openAIMessage {
+	switch {
+	case m.Role == "assistant":
+		msg := openAIMessage{Role: "assistant"}
+		if m.Content != "" || len(m.ToolCalls) == 0 {
+			msg.Content = &m.Content
+		}
+		for _, tc := range m.ToolCalls {
+			args := tc.Arguments
+			if args == "" {
+				args = "{}"
+			}
+			msg.ToolCalls = append(msg.ToolCalls, openAIToolCall{
+				ID:       tc.ID,
+				Type:     "function",
+				Function: openAIToolCallFunc{Name: tc.Name, Arguments: args},
+			})
+		}
+		return []openAIMessage{msg}
+	case m.Role == "tool" && len(m.ToolResults) > 0:
+		msgs := make([]openAIMessage, 0, len(m.ToolResults))
+		for _, r := range m.ToolResults {
+			content := r.Content
+			msgs = append(msgs, openAIMessage{Role: "tool", Content: &content, ToolCallID: r.ToolCallID})
+		}
+		return msgs
+	default:
+		// "system", "user" and bare "tool" turns carry plain content
+		return []openAIMessage{{Role: m.Role, Content: &m.Content}}
+	}
+}
+
+// fromOpenAIMessage maps an assistant message and usage back to the
+// engine-internal LLMResponse.
+//
+//nolint:gocritic // hugeParam requires pointer but openAIMessage is heavily used as value in Layer 0
+func fromOpenAIMessage(m openAIMessage, usage openAIUsage) LLMResponse {
+	res := LLMResponse{
+		TokenUsage: TokenUsage{
+			PromptTokens:     usage.PromptTokens,
+			CompletionTokens: usage.CompletionTokens,
+			TotalTokens:      usage.TotalTokens,
+		},
+	}
+	if m.Content != nil {
+		res.Content = *m.Content
+	}
+	if res.TokenUsage.TotalTokens == 0 {
+		res.TokenUsage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+
+	for i, tc := range m.ToolCalls {
+		id := tc.ID
+		if id == "" {
+			// Some servers omit IDs; synthesise one so results can be matched.
+			id = fmt.Sprintf("call_%s_%d", tc.Function.Name, i)
+		}
+		args := tc.Function.Arguments
+		if args == "" {
+			args = "{}"
+		}
+		res.ToolCalls = append(res.ToolCalls, ToolCall{ID: id, Name: tc.Function.Name, Arguments: args})
+	}
+	return res
+}
+
+// mergeToolCallDeltas folds streamed tool call fragments into calls, keyed by index.
+func mergeToolCallDeltas(calls map[int]*openAIToolCall, deltas []openAIToolCall) {
+	for _, d := range deltas {
+		tc, ok := calls[d.Index]
+		if !ok {
+			tc = &openAIToolCall{Index: d.Index, Type: "function"}
+			calls[d.Index] = tc
+		}
+		if d.ID != "" {
+			tc.ID = d.ID
+		}
+		if d.Function.Name != "" {
+			tc.Function.Name = d.Function.Name
+		}
+		tc.Function.Arguments += d.Function.Arguments
+	}
+}
+
+func sortedToolCalls(calls map[int]*openAIToolCall) []openAIToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	out := make([]openAIToolCall, 0, len(calls))
+	for _, tc := range calls {
+		out = append(out, *tc)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Index < out[j].Index })
+	return out
+}
+
+// toOpenAITool converts an engine-internal ToolManifest to the OpenAI wire format.
+//
+//nolint:gocritic // hugeParam requires pointer but ToolManifest is heavily used as value in Layer 0
+func toOpenAITool(t ToolManifest) openAITool {
+	params := t.Parameters
+	if len(params) == 0 || !json.Valid(params) {
+		params = json.RawMessage(`{"type":"object","properties":{},"required":[]}`)
+	}
+	return openAITool{
+		Type: "function",
+		Function: openAIFunction{
+			Name:        t.Name,
+			Description: t.Description,
+			Parameters:  params,
+		},
+	}
+}
@@ -0,0 +1,257 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// makeOpenAIServer serves body for every request and hands each decoded
+// request and its Authorization header to inspect, if non-nil.
+func makeOpenAIServer(t *testing.T, body string, statusCode int, inspect func(openAIChatRequest, string)) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		var req openAIChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if inspect != nil {
+			inspect(req, r.Header.Get("Authorization"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+func TestOpenAICompatAdapter_textResponseAndUsage(t *testing.T) {
+	var auth string
+	srv := makeOpenAIServer(t, `{"model":"qwen","choices":[{"message":{"role":"assistant","content":"The answer is 42."},"finish_reason":"stop"}],
+		"usage":{"prompt_tokens":10,"completion_tokens":20,"total_tokens":30}}`, http.StatusOK,
+		func(_ openAIChatRequest, a string) { auth = a })
+	defer srv.Close()
+
+	adapter := newTestOpenAICompatAdapter("qwen", srv.URL+"/v1", "sk-test")
+	res, err := adapter.GenerateWithTools(context.Background(), []Message{{Role: "user", Content: "What is the answer?"}}, nil)
+	if err != nil {
+		t.Fatalf("GenerateWithTools: %v", err)
+	}
+	if res.Content != "The answer is 42." || len(res.ToolCalls) != 0 {
+		t.Errorf("unexpected response %+v", res)
+	}
+	if res.TokenUsage != (TokenUsage{PromptTokens: 10, CompletionTokens: 20, TotalTokens: 30}) {
+		t.Errorf("unexpected usage %+v", res.TokenUsage)
+	}
+	if auth != "Bearer sk-test" {
+		t.Errorf("want bearer token, got %q", auth)
+	}
+}
+
+func TestOpenAICompatAdapter_noKeyOmitsAuthorization(t *testing.T) {
+	auth := "unset"
+	srv := makeOpenAIServer(t, `{"choices":[{"message":{"content":"ok"}}]}`, http.StatusOK,
+		func(_ openAIChatRequest, a string) { auth = a })
+	defer srv.Close()
+
+	if _, err := newTestOpenAICompatAdapter("m", srv.URL+"/v1", "").Generate(context.Background(), "sys", "hi"); err != nil {
+		t.Fatal(err)
+	}
+	if auth != "" {
+		t.Errorf("no Authorization header expected without a key, got %q", auth)
+	}
+}
+
+func TestOpenAICompatAdapter_toolCallKeepsID(t *testing.T) {
+	srv := makeOpenAIServer(t, `{"choices":[{"message":{"role":"assistant","content":null,
+		"tool_calls":[{"id":"call_abc","type":"function","function":{"name":"sys_info","arguments":"{\"verbose\":true}"}}]},
+		"finish_reason":"tool_calls"}]}`, http.StatusOK, func(req openAIChatRequest, _ string) {
+		if len(req.Tools) != 1 || req.Tools[0].Type != "function" || req.Tools[0].Function.Name != "sys_info" {
+			t.Errorf("unexpected tools %+v", req.Tools)
+		}
+	})
+	defer srv.Close()
+
+	adapter := newTestOpenAICompatAdapter("qwen", srv.URL+"/v1", "")
+	res, err := adapter.GenerateWithTools(context.Background(), []Message{{Role: "user", Content: "info"}},
+		[]ToolManifest{{Name: "sys_info", Description: "Returns current system metrics."}})
+	if err != nil {
+		t.Fatalf("GenerateWithTools: %v", err)
+	}
+	want := ToolCall{ID: "call_abc", Name: "sys_info", Arguments: `{"verbose":true}`}
+	if len(res.ToolCalls) != 1 || res.ToolCalls[0] != want {
+		t.Fatalf("want %+v, got %+v", want, res.ToolCalls)
+	}
+}
+
+func TestOpenAICompatAdapter_httpError_returnsStatusError(t *testing.T) {
+	srv := makeOpenAIServer(t, `{"error":{"message":"overloaded"}}`, http.StatusServiceUnavailable, nil)
+	defer srv.Close()
+
+	_, err := newTestOpenAICompatAdapter("m", srv.URL+"/v1", "").GenerateWithTools(context.Background(),
+		[]Message{{Role: "user", Content: "hello"}}, nil)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable || !statusErr.Temporary() {
+		t.Fatalf("want temporary StatusError, got %v", err)
+	}
+}
+
+func TestOpenAICompatAdapter_Name(t *testing.T) {
+	if got := NewOpenAICompatAdapter("http://localhost:8080/v1/", "", "llama-3.1-8b").Name(); got != "openai/llama-3.1-8b" {
+		t.Errorf("want name=openai/llama-3.1-8b, got %q", got)
+	}
+}
+
+// ---- message mapping tests --------------------------------------------------
+
+func TestToOpenAIMessages_toolResultsExpandPerCall(t *testing.T) {
+	msgs := toOpenAIMessages(Message{Role: "tool", ToolResults: []ToolResultMessage{
+		{ToolCallID: "call_1", Content: "cpu 3%"},
+		{ToolCallID: "call_2", Content: "denied", IsError: true},
+	}})
+	if len(msgs) != 2 {
+		t.Fatalf("want one message per result, got %d", len(msgs))
+	}
+	for i, id := range []string{"call_1", "call_2"} {
+		if msgs[i].Role != "tool" || msgs[i].ToolCallID != id {
+			t.Errorf("message %d: want role tool with tool_call_id %s, got %+v", i, id, msgs[i])
+		}
+	}
+	if *msgs[0].Content != "cpu 3%" {
+		t.Errorf("unexpected content %q", *msgs[0].Content)
+	}
+}
+
+func TestToOpenAIMessages_assistantWithToolCalls(t *testing.T) {
+	msgs := toOpenAIMessages(Message{Role: "assistant", ToolCalls: []ToolCall{{ID: "call_1", Name: "sys_info"}}})
+	raw, err := json.Marshal(msgs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","type":"function","function":{"name":"sys_info","arguments":"{}"}}]}`
+	if string(raw) != want {
+		t.Errorf("want %s\n got %s", want, raw)
+	}
+}
+
+func TestOpenAICompatAdapter_modelHintAndResponseFormat(t *testing.T) {
+	a := newTestOpenAICompatAdapter("qwen", "http://localhost/v1", "")
+	cases := map[string]string{
+		"":                               "qwen",
+		"gpt-4o":                         "gpt-4o",
+		"openai/gpt-4o":                  "gpt-4o",
+		"ollama/phi3":                    "qwen",
+		"meta-llama/Llama-3-8B-Instruct": "meta-llama/Llama-3-8B-Instruct",
+	}
+	for hint, want := range cases {
+		ctx := context.Background()
+		if hint != "" {
+			ctx = WithModelHint(ctx, hint)
+		}
+		if got := a.modelFor(ctx); got != want {
+			t.Errorf("hint %q: want model %q, got %q", hint, want, got)
+		}
+	}
+
+	msgs := []Message{{Role: "user", Content: "hi"}}
+	if req := a.buildChatRequest(context.Background(), msgs, nil, false); req.ResponseFormat != nil {
+		t.Errorf("response_format must be omitted without a hint, got %+v", req.ResponseFormat)
+	}
+	ctx := WithResponseFormat(context.Background(), json.RawMessage(`{"type":"object"}`))
+	body, err := json.Marshal(a.buildChatRequest(ctx, msgs, nil, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(body), `"response_format":{"type":"json_schema","json_schema":{"name":"response","schema":{"type":"object"}}}`) {
+		t.Errorf("request should carry the schema as response_format: %s", body)
+	}
+}
+
+// ---- GenerateWithToolsStream tests ------------------------------------------
+
+// makeOpenAIStreamServer serves the given SSE data payloads, flushing after each.
+func makeOpenAIStreamServer(t *testing.T, events []string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openAIChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !req.Stream || req.StreamOptions == nil {
+			t.Errorf("expected stream request with usage, got %+v err=%v", req, err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher, _ := w.(http.Flusher)
+		for _, ev := range events {
+			_, _ = w.Write([]byte("data: " + ev + "\n\n"))
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}))
+}
+
+func TestOpenAICompatAdapter_stream_assemblesContentAndToolCalls(t *testing.T) {
+	srv := makeOpenAIStreamServer(t, []string{
+		`{"choices":[{"delta":{"role":"assistant","content":"Let me "}}]}`,
+		`{"choices":[{"delta":{"content":"check."}}]}`,
+		`{"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_x","type":"function","function":{"name":"sys_info","arguments":""}}]}}]}`,
+		`{"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"verbose\":"}}]}}]}`,
+		`{"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"true}"}}]}}]}`,
+		`{"choices":[{"delta":{},"finish_reason":"tool_calls"}]}`,
+		`{"choices":[],"usage":{"prompt_tokens":7,"completion_tokens":3,"total_tokens":10}}`,
+		`[DONE]`,
+	})
+	defer srv.Close()
+
+	var chunks []string
+	res, err := newTestOpenAICompatAdapter("qwen", srv.URL+"/v1", "").GenerateWithToolsStream(context.Background(),
+		[]Message{{Role: "user", Content: "info"}}, nil, func(c StreamChunk) { chunks = append(chunks, c.Content) })
+	if err != nil {
+		t.Fatalf("GenerateWithToolsStream: %v", err)
+	}
+	if strings.Join(chunks, "|") != "Let me |check." || res.Content != "Let me check." {
+		t.Errorf("unexpected chunks %q / content %q", chunks, res.Content)
+	}
+	want := ToolCall{ID: "call_x", Name: "sys_info", Arguments: `{"verbose":true}`}
+	if len(res.ToolCalls) != 1 || res.ToolCalls[0] != want {
+		t.Errorf("want %+v, got %+v", want, res.ToolCalls)
+	}
+	if res.TokenUsage.TotalTokens != 10 {
+		t.Errorf("want 10 total tokens, got %d", res.TokenUsage.TotalTokens)
+	}
+}
+
+func TestOpenAICompatAdapter_stream_truncated(t *testing.T) {
+	srv := makeOpenAIStreamServer(t, []string{`{"choices":[{"delta":{"content":"par"}}]}`})
+	defer srv.Close()
+
+	_, err := newTestOpenAICompatAdapter("qwen", srv.URL+"/v1", "").GenerateWithToolsStream(context.Background(),
+		[]Message{{Role: "user", Content: "hi"}}, nil, nil)
+	if err == nil {
+		t.Fatal("expected error for stream without [DONE], got nil")
+	}
+}
+
+func TestOpenAICompatAdapter_stream_outlivesClientTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		flusher, _ := w.(http.Flusher)
+		_, _ = w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"slow \"}}]}\n\n"))
+		flusher.Flush()
+		time.Sleep(300 * time.Millisecond) // a long generation
+		_, _ = w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"answer\"}}]}\n\ndata: [DONE]\n\n"))
+	}))
+	defer srv.Close()
+
+	adapter := newTestOpenAICompatAdapter("qwen", srv.URL+"/v1", "")
+	adapter.http.Timeout = 100 * time.Millisecond
+	adapter.stream = streamingClient(adapter.http)
+	res, err := adapter.GenerateWithToolsStream(context.Background(), []Message{{Role: "user", Content: "q"}}, nil, nil)
+	if err != nil || res.Content != "slow answer" {
+		t.Fatalf("the client timeout must not cut a stream off: %q (%v)", res.Content, err)
+	}
+}
@@ -2,66 +2,92 @@ package llm
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 )
 
-// Orchestrator coordinates multiple routers and providers to fulfill a task.
-// The optional adapter field provides native tool-call support (GenerateWithTools).
-// When adapter is nil, GenerateWithTools falls back to a simple Execute call.
+// Orchestrator routes every request to the Provider its Router selects. It
+// implements LLMAdapter and StreamingAdapter, so an Engine can run ReAct
+// tasks through it to get cost or latency routing, fallback and retries.
 type Orchestrator struct {
-	router  Router
-	adapter LLMAdapter
+	router Router
 }
 
 func NewOrchestrator(router Router) *Orchestrator {
 	return &Orchestrator{router: router}
 }
 
-// NewOrchestratorWithAdapter constructs an Orchestrator that can execute both
-// plain tasks (via router) and multi-turn ReAct tool loops (via adapter).
-func NewOrchestratorWithAdapter(router Router, adapter LLMAdapter) *Orchestrator {
-	return &Orchestrator{router: router, adapter: adapter}
-}
+// Name returns the adapter identifier used in routing tables.
+func (o *Orchestrator) Name() string { return "orchestrator" }
 
-// Execute selects a provider via the router and runs the task with retry logic.
+// Execute runs a single-turn task on the selected provider.
 func (o *Orchestrator) Execute(ctx context.Context, task string) (string, error) {
-	provider, err := o.router.Select(ctx, task)
+	res, err := o.GenerateWithTools(ctx, []Message{{Role: "user", Content: task}}, nil)
 	if err != nil {
-		return "", fmt.Errorf("router_selection_failed: %w", err)
+		return "", err
 	}
+	return res.Content, nil
+}
 
-	return provider.Execute(ctx, task)
+// Generate is a convenience wrapper for single-turn text generation.
+func (o *Orchestrator) Generate(ctx context.Context, systemPrompt, userInput string) (string, error) {
+	return dispatch(ctx, o.router, userInput, func(ctx context.Context, p Provider) (string, error) {
+		return p.Generate(ctx, systemPrompt, userInput)
+	})
 }
 
-// GenerateWithTools selects the best provider for tool-use via the router and
-// delegates to the configured LLMAdapter. If no adapter is configured, it falls
-// back to the router-selected provider's Execute method (no tool parsing).
+// GenerateWithTools selects a provider for the conversation and delegates the
+// turn to it; the provider owns schema conversion, tool_call parsing and
+// token-usage accounting.
 func (o *Orchestrator) GenerateWithTools(ctx context.Context, messages []Message, tools []ToolManifest) (LLMResponse, error) {
-	// If a concrete LLMAdapter is wired in, delegate directly — it owns schema
-	// conversion, tool_call parsing, and token-usage accounting.
-	if o.adapter != nil {
-		return o.adapter.GenerateWithTools(ctx, messages, tools)
-	}
+	return dispatch(ctx, o.router, taskOf(messages), func(ctx context.Context, p Provider) (LLMResponse, error) {
+		return p.GenerateWithTools(ctx, messages, tools)
+	})
+}
 
-	// Fallback: use the router to pick a provider and issue the last user message
-	// as a plain text Execute call. This path produces no ToolCalls.
-	provider, err := o.router.Select(ctx, "tool_use_request")
-	if err != nil {
-		return LLMResponse{}, fmt.Errorf("router_selection_failed: %w", err)
+// GenerateWithToolsStream is GenerateWithTools, streaming when the selected
+// provider supports it.
+func (o *Orchestrator) GenerateWithToolsStream(ctx context.Context, messages []Message, tools []ToolManifest, onChunk func(StreamChunk)) (LLMResponse, error) {
+	return dispatch(withStreaming(ctx), o.router, taskOf(messages), func(ctx context.Context, p Provider) (LLMResponse, error) {
+		return GenerateStream(ctx, p, messages, tools, onChunk)
+	})
+}
+
+// dispatch runs call on the provider the router selects for task. A provider
+// whose circuit breaker rejects the call, typically because a concurrent turn
+// holds its half-open trial slot, is excluded and the router asked again, so
+// each provider is tried at most once.
+func dispatch[T any](ctx context.Context, router Router, task string, call func(context.Context, Provider) (T, error)) (T, error) {
+	var (
+		zero     T
+		rejected error
+	)
+	for {
+		provider, err := router.Select(ctx, task)
+		switch {
+		case err != nil && rejected != nil:
+			return zero, rejected
+		case err != nil:
+			return zero, fmt.Errorf("router_selection_failed: %w", err)
+		case slices.Contains(excludedFrom(ctx), provider):
+			return zero, rejected // the router does not honour exclusions
+		}
+		res, err := call(ctx, provider)
+		if !errors.Is(err, ErrCircuitOpen) {
+			return res, err
+		}
+		rejected = err
+		ctx = withExcluded(ctx, provider)
 	}
+}
 
-	// Extract the last user-role message as the task string.
-	task := ""
+// taskOf describes a conversation to the router by its latest user message.
+func taskOf(messages []Message) string {
 	for i := len(messages) - 1; i >= 0; i-- {
 		if messages[i].Role == "user" {
-			task = messages[i].Content
-			break
+			return messages[i].Content
 		}
 	}
-
-	content, err := provider.Execute(ctx, task)
-	if err != nil {
-		return LLMResponse{}, err
-	}
-	return LLMResponse{Content: content}, nil
+	return ""
 }
@@ -3,39 +3,19 @@ package llm
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 )
 
 // ---- stubs ------------------------------------------------------------------
 
-type stubProvider struct {
-	name   string
-	status Status
-	reply  string
-	err    error
-}
-
-func (s *stubProvider) Name() string            { return s.name }
-func (s *stubProvider) Status() Status          { return s.status }
-func (s *stubProvider) Priority() Priority      { return 1 }
-func (s *stubProvider) Metadata() ModelMetadata { return ModelMetadata{} }
-func (s *stubProvider) Execute(_ context.Context, _ string) (string, error) {
-	return s.reply, s.err
-}
-
-type stubRouter struct {
-	p   Provider
-	err error
-}
-
-func (r *stubRouter) Select(_ context.Context, _ string) (Provider, error) { return r.p, r.err }
-
 type stubAdapter struct {
-	res LLMResponse
-	err error
+	name string
+	res  LLMResponse
+	err  error
 }
 
-func (a *stubAdapter) Name() string { return "stub" }
+func (a *stubAdapter) Name() string { return a.name }
 func (a *stubAdapter) Generate(_ context.Context, _, _ string) (string, error) {
 	return a.res.Content, a.err
 }
@@ -43,10 +23,21 @@ func (a *stubAdapter) GenerateWithTools(_ context.Context, _ []Message, _ []Tool
 	return a.res, a.err
 }
 
+type stubRouter struct {
+	p    Provider
+	err  error
+	task string // last task passed to Select
+}
+
+func (r *stubRouter) Select(_ context.Context, task string) (Provider, error) {
+	r.task = task
+	return r.p, r.err
+}
+
 // ---- Execute tests ----------------------------------------------------------
 
 func TestOrchestrator_Execute_success(t *testing.T) {
-	router := &stubRouter{p: &stubProvider{name: "p1", status: StatusHealthy, reply: "done"}}
+	router := &stubRouter{p: NewProvider(&stubAdapter{res: LLMResponse{Content: "done"}}, 1, ModelMetadata{})}
 	o := NewOrchestrator(router)
 	got, err := o.Execute(context.Background(), "task")
 	if err != nil {
@@ -66,15 +57,18 @@ func TestOrchestrator_Execute_routerError(t *testing.T) {
 	}
 }
 
-// ---- GenerateWithTools with adapter -----------------------------------------
+// ---- GenerateWithTools tests ------------------------------------------------
 
-func TestOrchestrator_GenerateWithTools_delegatesToAdapter(t *testing.T) {
+func TestOrchestrator_GenerateWithTools_routesToSelectedProvider(t *testing.T) {
 	want := LLMResponse{Content: "from adapter", ToolCalls: []ToolCall{{Name: "sys_info"}}}
-	adapter := &stubAdapter{res: want}
-	o := NewOrchestratorWithAdapter(&stubRouter{p: &stubProvider{status: StatusHealthy}}, adapter)
+	router := &stubRouter{p: NewProvider(&stubAdapter{res: want}, 1, ModelMetadata{})}
+	o := NewOrchestrator(router)
 
 	got, err := o.GenerateWithTools(context.Background(), []Message{
+		{Role: "system", Content: "You are an agent."},
 		{Role: "user", Content: "run sys_info"},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "c1", Name: "sys_info"}}},
+		{Role: "tool", ToolResults: []ToolResultMessage{{ToolCallID: "c1", Content: "ok"}}},
 	}, nil)
 	if err != nil {
 		t.Fatalf("GenerateWithTools: %v", err)
@@ -85,43 +79,24 @@ func TestOrchestrator_GenerateWithTools_delegatesToAdapter(t *testing.T) {
 	if len(got.ToolCalls) != 1 || got.ToolCalls[0].Name != "sys_info" {
 		t.Errorf("unexpected tool calls: %v", got.ToolCalls)
 	}
+	if router.task != "run sys_info" {
+		t.Errorf("router should see the latest user message, got %q", router.task)
+	}
 }
 
-func TestOrchestrator_GenerateWithTools_adapterError_propagated(t *testing.T) {
-	adapter := &stubAdapter{err: errors.New("LLM unavailable")}
-	o := NewOrchestratorWithAdapter(&stubRouter{p: &stubProvider{status: StatusHealthy}}, adapter)
+func TestOrchestrator_GenerateWithTools_providerError_propagated(t *testing.T) {
+	router := &stubRouter{p: NewProvider(&stubAdapter{err: errors.New("LLM unavailable")}, 1, ModelMetadata{})}
+	o := NewOrchestrator(router)
 
 	_, err := o.GenerateWithTools(context.Background(), []Message{
 		{Role: "user", Content: "hello"},
 	}, nil)
 	if err == nil {
-		t.Fatal("expected error from adapter, got nil")
+		t.Fatal("expected error from provider, got nil")
 	}
 }
 
-// ---- GenerateWithTools without adapter (fallback path) ----------------------
-
-func TestOrchestrator_GenerateWithTools_fallback_usesLastUserMessage(t *testing.T) {
-	router := &stubRouter{p: &stubProvider{status: StatusHealthy, reply: "fallback answer"}}
-	o := NewOrchestrator(router) // no adapter
-
-	msgs := []Message{
-		{Role: "system", Content: "You are an agent."},
-		{Role: "user", Content: "What time is it?"},
-	}
-	got, err := o.GenerateWithTools(context.Background(), msgs, nil)
-	if err != nil {
-		t.Fatalf("GenerateWithTools fallback: %v", err)
-	}
-	if got.Content != "fallback answer" {
-		t.Errorf("want content=fallback answer, got %q", got.Content)
-	}
-	if len(got.ToolCalls) != 0 {
-		t.Errorf("fallback path must not return tool calls")
-	}
-}
-
-func TestOrchestrator_GenerateWithTools_fallback_routerError(t *testing.T) {
+func TestOrchestrator_GenerateWithTools_routerError(t *testing.T) {
 	router := &stubRouter{err: errors.New("all offline")}
 	o := NewOrchestrator(router)
 
@@ -133,14 +108,54 @@ func TestOrchestrator_GenerateWithTools_fallback_routerError(t *testing.T) {
 	}
 }
 
-func TestNewOrchestratorWithAdapter_nilAdapter_usesRouterFallback(t *testing.T) {
-	router := &stubRouter{p: &stubProvider{status: StatusHealthy, reply: "ok"}}
-	o := NewOrchestratorWithAdapter(router, nil)
-	got, err := o.GenerateWithTools(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+func TestOrchestrator_fallbackSkipsOfflineAdapter(t *testing.T) {
+	primary := NewProvider(&stubAdapter{name: "primary", res: LLMResponse{Content: "primary"}}, 1, ModelMetadata{})
+	secondary := NewProvider(&stubAdapter{name: "secondary", res: LLMResponse{Content: "secondary"}}, 2, ModelMetadata{})
+	o := NewOrchestrator(NewFallbackRouter([]Provider{secondary, primary}))
+
+	ask := func() string {
+		t.Helper()
+		res, err := o.GenerateWithTools(context.Background(), []Message{{Role: "user", Content: "hi"}}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.Content
+	}
+	if got := ask(); got != "primary" {
+		t.Fatalf("want primary, got %q", got)
+	}
+	primary.SetStatus(StatusOffline)
+	if got := ask(); got != "secondary" {
+		t.Fatalf("want failover to secondary, got %q", got)
+	}
+}
+
+func TestOrchestrator_streamsThroughProvider(t *testing.T) {
+	router := &stubRouter{p: NewProvider(&stubAdapter{res: LLMResponse{Content: "whole"}}, 1, ModelMetadata{})}
+	var chunks []string
+	res, err := GenerateStream(context.Background(), NewOrchestrator(router), []Message{{Role: "user", Content: "hi"}}, nil,
+		func(c StreamChunk) { chunks = append(chunks, c.Content) })
+	if err != nil || res.Content != "whole" || len(chunks) != 1 || chunks[0] != "whole" {
+		t.Fatalf("unexpected stream result %q / %q (%v)", res.Content, chunks, err)
+	}
+}
+
+func TestOrchestrator_reroutesAroundOpenCircuit(t *testing.T) {
+	busy := NewProvider(&stubAdapter{name: "busy", err: fmt.Errorf("%w: busy awaiting trial result", ErrCircuitOpen)}, 1, ModelMetadata{})
+	spare := NewProvider(&stubAdapter{name: "spare", res: LLMResponse{Content: "spare"}}, 2, ModelMetadata{})
+	msgs := []Message{{Role: "user", Content: "hi"}}
+
+	res, err := NewOrchestrator(NewFallbackRouter([]Provider{busy, spare})).GenerateWithTools(context.Background(), msgs, nil)
+	if err != nil || res.Content != "spare" {
+		t.Fatalf("want re-route to spare, got %q (%v)", res.Content, err)
+	}
+
+	_, err = NewOrchestrator(NewFallbackRouter([]Provider{busy})).GenerateWithTools(context.Background(), msgs, nil)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("with no alternative the rejection is returned, got %v", err)
 	}
-	if got.Content != "ok" {
-		t.Errorf("want ok, got %q", got.Content)
+	_, err = NewOrchestrator(&stubRouter{p: busy}).GenerateWithTools(context.Background(), msgs, nil)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("a router ignoring exclusions must not loop, got %v", err)
 	}
 }
@@ -0,0 +1,34 @@
+package llm
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// AdapterProvider attaches routing metadata to an LLMAdapter so routers can
+// select among real, tool-capable adapters.
+type AdapterProvider struct {
+	LLMAdapter
+	priority Priority
+	metadata ModelMetadata
+	status   atomic.Value // Status
+}
+
+// NewProvider wraps adapter as a healthy Provider.
+func NewProvider(adapter LLMAdapter, priority Priority, metadata ModelMetadata) *AdapterProvider {
+	p := &AdapterProvider{LLMAdapter: adapter, priority: priority, metadata: metadata}
+	p.status.Store(StatusHealthy)
+	return p
+}
+
+func (p *AdapterProvider) Status() Status          { return p.status.Load().(Status) }
+func (p *AdapterProvider) Priority() Priority      { return p.priority }
+func (p *AdapterProvider) Metadata() ModelMetadata { return p.metadata }
+
+// SetStatus marks the provider healthy, degraded or offline for routing.
+func (p *AdapterProvider) SetStatus(s Status) { p.status.Store(s) }
+
+// GenerateWithToolsStream streams when the wrapped adapter supports it.
+func (p *AdapterProvider) GenerateWithToolsStream(ctx context.Context, messages []Message, tools []ToolManifest, onChunk func(StreamChunk)) (LLMResponse, error) {
+	return GenerateStream(ctx, p.LLMAdapter, messages, tools, onChunk)
+}
@@ -24,17 +24,59 @@ func (p *RetryingProvider) Status() Status          { return p.base.Status() }
 func (p *RetryingProvider) Priority() Priority      { return p.base.Priority() }
 func (p *RetryingProvider) Metadata() ModelMetadata { return p.base.Metadata() }
 
-func (p *RetryingProvider) Execute(ctx context.Context, task string) (string, error) {
+func (p *RetryingProvider) Generate(ctx context.Context, systemPrompt, userInput string) (string, error) {
+	var out string
+	err := p.retry(ctx, func() (bool, error) {
+		var err error
+		out, err = p.base.Generate(ctx, systemPrompt, userInput)
+		return true, err
+	})
+	return out, err
+}
+
+func (p *RetryingProvider) GenerateWithTools(ctx context.Context, messages []Message, tools []ToolManifest) (LLMResponse, error) {
+	var res LLMResponse
+	err := p.retry(ctx, func() (bool, error) {
+		var err error
+		res, err = p.base.GenerateWithTools(ctx, messages, tools)
+		return true, err
+	})
+	return res, err
+}
+
+// GenerateWithToolsStream retries only until the first chunk has been
+// delivered; after that a failure is returned rather than replaying content.
+func (p *RetryingProvider) GenerateWithToolsStream(ctx context.Context, messages []Message, tools []ToolManifest, onChunk func(StreamChunk)) (LLMResponse, error) {
+	var (
+		res      LLMResponse
+		streamed bool
+	)
+	err := p.retry(ctx, func() (bool, error) {
+		var err error
+		res, err = GenerateStream(ctx, p.base, messages, tools, func(c StreamChunk) {
+			streamed = true
+			if onChunk != nil {
+				onChunk(c)
+			}
+		})
+		return !streamed, err
+	})
+	return res, err
+}
+
+// retry runs attempt up to maxRetries times while it fails and reports the
+// failure as retryable.
+func (p *RetryingProvider) retry(ctx context.Context, attempt func() (bool, error)) error {
 	var lastErr error
 	for i := range p.maxRetries {
-		res, err := p.base.Execute(ctx, task)
+		retryable, err := attempt()
 		if err == nil {
-			return res, nil
+			return nil
 		}
 		lastErr = err
 
-		// Skip wait if it's the last attempt
-		if i == p.maxRetries-1 {
+		// Give up on unretryable failures and skip the wait after the last attempt
+		if !retryable || i == p.maxRetries-1 {
 			break
 		}
 
@@ -44,8 +86,8 @@ func (p *RetryingProvider) Execute(ctx context.Context, task string) (string, er
 		select {
 		case <-time.After(backoff):
 		case <-ctx.Done():
-			return "", ctx.Err()
+			return ctx.Err()
 		}
 	}
-	return "", lastErr
+	return lastErr
 }
@@ -2,8 +2,13 @@ package llm
 
 import (
 	"context"
+	"errors"
+	"slices"
 )
 
+// ErrNoHealthyProvider is returned by routers when no provider is usable.
+var ErrNoHealthyProvider = errors.New("no healthy LLM providers available")
+
 // Router defines the logic for selecting the most appropriate LLM provider
 // based on task complexity, cost, and availability.
 type Router interface {
@@ -30,12 +35,70 @@ type ModelMetadata struct {
 // Priority represents the selection rank (lower is higher priority).
 type Priority int
 
-// Provider is a specialized interface that the Router works with.
-// It abstracts the underlying LLMAdapter to add routing-specific metadata.
+// Provider is an LLMAdapter carrying the routing metadata a Router selects on.
+// Wrap a concrete adapter with NewProvider.
+//
+// Providers are identified by interface equality: the orchestrator excludes a
+// rejected provider by comparing it with ==, and AdaptiveLatencyRouter.Stats
+// is keyed by provider. Implementations must therefore be comparable, and
+// should be pointer types so that distinct instances never compare equal; a
+// non-comparable dynamic type, such as a struct value holding a slice, map or
+// func, makes those comparisons panic. All providers in this package are
+// pointers.
 type Provider interface {
-	Name() string
+	LLMAdapter
 	Status() Status
 	Priority() Priority
 	Metadata() ModelMetadata
-	Execute(ctx context.Context, task string) (string, error)
+}
+
+type (
+	excludedKey  struct{}
+	streamingKey struct{}
+)
+
+// withStreaming marks ctx as selecting a provider for a streamed turn, so
+// routers can rank by time to first token.
+func withStreaming(ctx context.Context) context.Context {
+	return context.WithValue(ctx, streamingKey{}, true)
+}
+
+func streamingFrom(ctx context.Context) bool {
+	streamed, _ := ctx.Value(streamingKey{}).(bool)
+	return streamed
+}
+
+// withExcluded returns a context asking routers not to select p again, for
+// example after its circuit breaker rejected a call.
+func withExcluded(ctx context.Context, p Provider) context.Context {
+	prev := excludedFrom(ctx)
+	return context.WithValue(ctx, excludedKey{}, append(prev[:len(prev):len(prev)], p))
+}
+
+func excludedFrom(ctx context.Context) []Provider {
+	excluded, _ := ctx.Value(excludedKey{}).([]Provider)
+	return excluded
+}
+
+// usable returns the healthy providers, or the degraded ones if none are
+// healthy, preserving order. Offline providers and those excluded from ctx
+// are never returned.
+func usable(ctx context.Context, providers []Provider) []Provider {
+	excluded := excludedFrom(ctx)
+	var healthy, degraded []Provider
+	for _, p := range providers {
+		if slices.Contains(excluded, p) {
+			continue
+		}
+		switch p.Status() {
+		case StatusHealthy:
+			healthy = append(healthy, p)
+		case StatusDegraded:
+			degraded = append(degraded, p)
+		}
+	}
+	if len(healthy) > 0 {
+		return healthy
+	}
+	return degraded
 }
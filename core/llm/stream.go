@@ -0,0 +1,56 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+)
+
+// StreamChunk is an incremental fragment of an assistant turn, delivered while
+// the model is still generating.
+type StreamChunk struct {
+	Content string
+}
+
+// StreamingAdapter is implemented by adapters that can deliver partial content
+// as it is produced. onChunk is invoked synchronously for every fragment; the
+// returned LLMResponse is the fully assembled turn, identical in shape to what
+// GenerateWithTools would have returned.
+type StreamingAdapter interface {
+	LLMAdapter
+	GenerateWithToolsStream(ctx context.Context, messages []Message, tools []ToolManifest, onChunk func(StreamChunk)) (LLMResponse, error)
+}
+
+// GenerateStream issues a tool-enabled generation and reports content through
+// onChunk. Adapters that implement StreamingAdapter stream natively; all other
+// adapters degrade gracefully to a single chunk carrying the complete content.
+func GenerateStream(ctx context.Context, adapter LLMAdapter, messages []Message, tools []ToolManifest, onChunk func(StreamChunk)) (LLMResponse, error) {
+	if sa, ok := adapter.(StreamingAdapter); ok {
+		return sa.GenerateWithToolsStream(ctx, messages, tools, onChunk)
+	}
+
+	res, err := adapter.GenerateWithTools(ctx, messages, tools)
+	if err != nil {
+		return LLMResponse{}, err
+	}
+	if res.Content != "" && onChunk != nil {
+		onChunk(StreamChunk{Content: res.Content})
+	}
+	return res, nil
+}
+
+// streamingClient derives the client for streamed requests from c. c.Timeout
+// covers reading the whole body and would cut a long generation off, so it
+// bounds only the wait for response headers; the request context bounds the
+// rest. The result should be kept, as it owns its connection pool.
+func streamingClient(c *http.Client) *http.Client {
+	base, ok := c.Transport.(*http.Transport)
+	if c.Transport == nil {
+		base, ok = http.DefaultTransport.(*http.Transport)
+	}
+	if !ok {
+		return &http.Client{Transport: c.Transport, CheckRedirect: c.CheckRedirect, Jar: c.Jar}
+	}
+	t := base.Clone()
+	t.ResponseHeaderTimeout = c.Timeout
+	return &http.Client{Transport: t, CheckRedirect: c.CheckRedirect, Jar: c.Jar}
+}
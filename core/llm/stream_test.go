@@ -0,0 +1,60 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type stubStreamAdapter struct {
+	stubAdapter
+	chunks []string
+}
+
+func (a *stubStreamAdapter) GenerateWithToolsStream(_ context.Context, _ []Message, _ []ToolManifest, onChunk func(StreamChunk)) (LLMResponse, error) {
+	for _, c := range a.chunks {
+		onChunk(StreamChunk{Content: c})
+	}
+	return a.res, a.err
+}
+
+func TestGenerateStream_nativeStreaming(t *testing.T) {
+	a := &stubStreamAdapter{stubAdapter: stubAdapter{res: LLMResponse{Content: "ab"}}, chunks: []string{"a", "b"}}
+	var got []string
+	res, err := GenerateStream(context.Background(), a, nil, nil, func(c StreamChunk) { got = append(got, c.Content) })
+	if err != nil {
+		t.Fatalf("GenerateStream: %v", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("want 2 native chunks, got %d", len(got))
+	}
+	if res.Content != "ab" {
+		t.Errorf("want content=ab, got %q", res.Content)
+	}
+}
+
+func TestGenerateStream_degradesToSingleChunk(t *testing.T) {
+	a := &stubAdapter{res: LLMResponse{Content: "whole answer"}}
+	var got []string
+	res, err := GenerateStream(context.Background(), a, nil, nil, func(c StreamChunk) { got = append(got, c.Content) })
+	if err != nil {
+		t.Fatalf("GenerateStream: %v", err)
+	}
+	if len(got) != 1 || got[0] != "whole answer" {
+		t.Errorf("want one chunk with full content, got %q", got)
+	}
+	if res.Content != "whole answer" {
+		t.Errorf("want content passthrough, got %q", res.Content)
+	}
+}
+
+func TestGenerateStream_propagatesError(t *testing.T) {
+	a := &stubAdapter{err: errors.New("down")}
+	called := false
+	if _, err := GenerateStream(context.Background(), a, nil, nil, func(StreamChunk) { called = true }); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if called {
+		t.Error("onChunk must not be invoked on failure")
+	}
+}
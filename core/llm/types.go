@@ -30,6 +30,9 @@ type LLMResponse struct {
 	Content    string
 	ToolCalls  []ToolCall
 	TokenUsage TokenUsage
+	// StopReason is why generation ended, as reported by the provider
+	// (e.g. "end_turn", "tool_use", "max_tokens"). Empty if unreported.
+	StopReason string
 }
 
 // Message represents a single turn in a conversational ReAct loop history.
@@ -69,4 +72,12 @@ type ToolManifest struct {
 	Capabilities []Capability    `json:"capabilities"`
 	MaxRuntimeMs int             `json:"max_runtime_ms"`
 	MemoryLimit  int             `json:"memory_limit_mb"`
+	// NoParallel marks a tool that must not run concurrently with other tool
+	// calls from the same model turn (e.g. it mutates shared state).
+	NoParallel bool `json:"no_parallel,omitempty"`
+	// OutputSchema optionally declares a JSON Schema the tool's output must satisfy.
+	OutputSchema json.RawMessage `json:"output_schema,omitempty"`
+	// CacheTTLMs opts an idempotent, read-only tool into result caching: a call
+	// with the same canonical arguments within the TTL reuses the earlier output.
+	CacheTTLMs int `json:"cache_ttl_ms,omitempty"`
 }
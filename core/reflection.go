@@ -0,0 +1,196 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+const (
+	// defaultReflectionRounds bounds critic reviews when ReflectionConfig.MaxRounds is unset.
+	defaultReflectionRounds = 2
+	// maxReviewExcerpt truncates each transcript entry shown to the critic.
+	maxReviewExcerpt = 2000
+)
+
+const criticPrompt = "You are a meticulous reviewer. Check the proposed final answer against the task and the " +
+	"evidence in the transcript. Reject answers that are unsupported, contradicted by tool results, incomplete or " +
+	"wrong, and say concretely what must be fixed. " +
+	`Reply only with JSON {"approved": true|false, "feedback": "..."}.`
+
+var criticVerdictSchema = json.RawMessage(`{
+	"type": "object",
+	"properties": {"approved": {"type": "boolean"}, "feedback": {"type": "string"}},
+	"required": ["approved"]
+}`)
+
+// ReflectionConfig configures the critic pass; see WithReflection.
+type ReflectionConfig struct {
+	// Critic reviews candidate answers. Nil means the engine's own adapter.
+	Critic llm.LLMAdapter
+	// Model is passed to the critic as a model hint, e.g. to route reviews to
+	// a stronger model behind a multi-provider adapter.
+	Model string
+	// MaxRounds bounds the reviews per task. An answer rejected in the last
+	// round is returned as is; its verdict remains in Result.Reflections.
+	// Zero means defaultReflectionRounds.
+	MaxRounds int
+}
+
+// ReflectionVerdict is the outcome of one critic review.
+type ReflectionVerdict struct {
+	Round    int
+	Approved bool
+	// Inconclusive is set when the critic's reply was not a valid verdict.
+	// Such a review neither approves nor rejects the answer.
+	Inconclusive bool
+	Feedback     string
+}
+
+// WithReflection enables a self-critique pass: before a task returns its final
+// answer, a critic reviews the transcript and answer and either approves it or
+// sends its feedback back into the agent loop for another attempt. Verdicts
+// are reported in Result.Reflections and written to the audit chain. In replay
+// mode the recorded critic responses are served instead of calling the critic.
+func (e *Engine) WithReflection(cfg ReflectionConfig) *Engine {
+	if cfg.MaxRounds <= 0 {
+		cfg.MaxRounds = defaultReflectionRounds
+	}
+	e.reflection = &cfg
+	return e
+}
+
+// criticAdapter returns the model that reviews answers.
+func (e *Engine) criticAdapter() llm.LLMAdapter {
+	if e.replay != nil || e.reflection.Critic == nil {
+		return e.adapter
+	}
+	return e.reflection.Critic
+}
+
+// reflect reviews a candidate final answer. A non-nil message carries the
+// critic's feedback and should be fed back to the model like a Finish retry.
+// An inconclusive review is repeated while rounds remain; if none do, the
+// answer is returned unapproved.
+func (r *AgentRun) reflect(ctx context.Context, messages []llm.Message, answer string) (*llm.Message, error) {
+	e := r.engine
+	if e.reflection == nil {
+		return nil, nil
+	}
+	var verdict ReflectionVerdict
+	for verdict.Round == 0 || verdict.Inconclusive {
+		round := len(r.res.Reflections) + 1
+		if round > e.reflection.MaxRounds {
+			return nil, nil
+		}
+		var err error
+		verdict, err = r.critique(ctx, round, messages, answer)
+		if err != nil {
+			return nil, fmt.Errorf("reflection_round_%d: %w", round, err)
+		}
+		r.res.Reflections = append(r.res.Reflections, verdict)
+
+		e.logAudit(ctx, fmt.Sprintf("%s-reflection-%d", r.Task.ID, round), "AUDIT_REFLECTION", "critic", map[string]interface{}{
+			"task_id": r.Task.ID, "round": round, "approved": verdict.Approved,
+			"inconclusive": verdict.Inconclusive, "feedback": verdict.Feedback,
+		})
+		if verdict.Inconclusive {
+			WithTask(ctx, r.Task.ID).Warn("reflection_inconclusive", slog.Int("round", round))
+			continue
+		}
+		WithTask(ctx, r.Task.ID).Info("reflection_reviewed",
+			slog.Int("round", round),
+			slog.Bool("approved", verdict.Approved),
+		)
+	}
+
+	if verdict.Approved || verdict.Round == e.reflection.MaxRounds {
+		return nil, nil
+	}
+	return &llm.Message{Role: "user", Content: "A reviewer rejected your final answer:\n" + verdict.Feedback +
+		"\nAddress this feedback, using tools if needed, then give a revised final answer."}, nil
+}
+
+// critique asks the critic for a verdict. The call is recorded in the
+// transcript and charged to the task's token budget, but bypasses the LLM
+// interceptor chain: the critic sees no tools and no fresh user input.
+func (r *AgentRun) critique(ctx context.Context, round int, messages []llm.Message, answer string) (ReflectionVerdict, error) {
+	e := r.engine
+	ctx = llm.WithResponseFormat(ctx, criticVerdictSchema)
+	if e.reflection.Model != "" {
+		ctx = llm.WithModelHint(ctx, e.reflection.Model)
+	}
+	prompt := []llm.Message{
+		{Role: "system", Content: criticPrompt},
+		{Role: "user", Content: reviewBrief(r.Task.Input, messages, answer)},
+	}
+
+	start := time.Now()
+	resp, err := e.criticAdapter().GenerateWithTools(ctx, prompt, nil)
+	e.record(&TranscriptRecord{
+		Type: TranscriptLLM, TaskID: r.Task.ID, Iteration: r.iteration, Messages: prompt, Response: &resp,
+		Error: errorString(err), ErrorKinds: errorKinds(err), DurationMs: time.Since(start).Milliseconds(),
+	})
+	if err != nil {
+		return ReflectionVerdict{}, err
+	}
+	err = r.scope.meter.charge(resp.TokenUsage)
+	r.res.Usage = r.scope.meter.total()
+	if err != nil {
+		return ReflectionVerdict{}, err
+	}
+
+	var reply struct {
+		Approved *bool  `json:"approved"`
+		Feedback string `json:"feedback"`
+	}
+	if err := json.Unmarshal([]byte(extractJSON(resp.Content)), &reply); err != nil || reply.Approved == nil {
+		// The critic is advisory: an unreadable verdict must not fail the task,
+		// but it must not pass the answer either.
+		return ReflectionVerdict{Round: round, Inconclusive: true, Feedback: "critic reply was not a valid verdict"}, nil
+	}
+	return ReflectionVerdict{Round: round, Approved: *reply.Approved, Feedback: reply.Feedback}, nil
+}
+
+// reviewBrief renders the task, its history and the candidate answer for the critic.
+func reviewBrief(input string, messages []llm.Message, answer string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Task:\n%s\n\nTranscript:\n", input)
+	for _, m := range messages {
+		switch m.Role {
+		case "system":
+			continue
+		case "tool":
+			for _, tr := range m.ToolResults {
+				status := "result"
+				if tr.IsError {
+					status = "error"
+				}
+				fmt.Fprintf(&b, "[tool %s] %s\n", status, excerpt(tr.Content))
+			}
+		default:
+			if m.Content != "" {
+				fmt.Fprintf(&b, "[%s] %s\n", m.Role, excerpt(m.Content))
+			}
+			for _, c := range m.ToolCalls {
+				fmt.Fprintf(&b, "[%s calls %s] %s\n", m.Role, c.Name, excerpt(c.Arguments))
+			}
+		}
+	}
+	fmt.Fprintf(&b, "\nProposed final answer:\n%s", answer)
+	return b.String()
+}
+
+func excerpt(s string) string {
+	if len(s) <= maxReviewExcerpt {
+		return s
+	}
+	return strings.ToValidUTF8(s[:maxReviewExcerpt], "") + "…"
+}
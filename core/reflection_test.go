@@ -0,0 +1,123 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+func TestReflection_feedbackDrivesAnotherIteration(t *testing.T) {
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{
+		{Content: "there are 2 things"},
+		{ToolCalls: []llm.ToolCall{{ID: "c1", Name: "count"}}},
+		{Content: "there are 3 things"},
+	}}
+	critic := &scriptedLLM{responses: []llm.LLMResponse{
+		{Content: `{"approved":false,"feedback":"nothing was counted"}`, TokenUsage: llm.TokenUsage{TotalTokens: 5}},
+		{Content: `{"approved":true}`, TokenUsage: llm.TokenUsage{TotalTokens: 5}},
+	}}
+	al := &MockAuditLogger{}
+	engine := NewEngine(adapter, 1, 1).WithAuditLogger(al).WithReflection(ReflectionConfig{Critic: critic, Model: "strong"})
+	tool := &countingTool{name: "count"}
+	registerAll(t, engine, tool)
+
+	res := runSingleTask(t, engine, &Task{ID: "reflect", Input: "how many?"})
+	if res.Error != nil || res.Output != "there are 3 things" {
+		t.Fatalf("unexpected result %q (err %v)", res.Output, res.Error)
+	}
+	if tool.count() != 1 {
+		t.Errorf("feedback should lead to a tool call, got %d runs", tool.count())
+	}
+	if len(res.Reflections) != 2 || res.Reflections[0].Approved || !res.Reflections[1].Approved {
+		t.Errorf("unexpected verdicts %+v", res.Reflections)
+	}
+	if fb := adapter.requests[1]; !strings.Contains(fb[len(fb)-1].Content, "nothing was counted") {
+		t.Errorf("model should receive the critic's feedback, got %+v", fb[len(fb)-1])
+	}
+	if critic.hints[0] != "strong" || len(critic.formats[0]) == 0 {
+		t.Errorf("critic should get the model hint and verdict format, got %q", critic.hints[0])
+	}
+	if brief := critic.requests[1][1].Content; !strings.Contains(brief, "[tool result]") || !strings.Contains(brief, "there are 3 things") {
+		t.Errorf("critic should review the transcript and answer, got:\n%s", brief)
+	}
+	if res.Usage.TotalTokens != 10 {
+		t.Errorf("critic usage should be charged to the task, got %d", res.Usage.TotalTokens)
+	}
+
+	audited := 0
+	for _, ev := range al.Events {
+		if ev.Type == "AUDIT_REFLECTION" {
+			audited++
+		}
+	}
+	if audited != 2 {
+		t.Errorf("want 2 AUDIT_REFLECTION events, got %d", audited)
+	}
+}
+
+func TestReflection_lastRoundRejectionIsReported(t *testing.T) {
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{{Content: "maybe"}}}
+	critic := &scriptedLLM{responses: []llm.LLMResponse{{Content: `{"approved":false,"feedback":"vague"}`}}}
+	engine := NewEngine(adapter, 1, 1).WithReflection(ReflectionConfig{Critic: critic, MaxRounds: 1})
+
+	res := runSingleTask(t, engine, &Task{ID: "stubborn", Input: "go"})
+	if res.Error != nil || res.Output != "maybe" {
+		t.Fatalf("unexpected result %q (err %v)", res.Output, res.Error)
+	}
+	if adapter.callCount() != 1 || len(res.Reflections) != 1 || res.Reflections[0].Approved {
+		t.Errorf("want one rejected review and no retry, got %d calls, %+v", adapter.callCount(), res.Reflections)
+	}
+}
+
+func TestReflection_replaysRecordedVerdicts(t *testing.T) {
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{{Content: "draft"}, {Content: "final"}}}
+	critic := &scriptedLLM{responses: []llm.LLMResponse{
+		{Content: `{"approved":false,"feedback":"expand"}`},
+		{Content: `{"approved":true}`},
+	}}
+	var transcript bytes.Buffer
+	engine := NewEngine(adapter, 1, 1).WithTranscript(NewTranscriptWriter(&transcript)).WithReflection(ReflectionConfig{Critic: critic})
+	runSingleTask(t, engine, &Task{ID: "incident", Input: "go"})
+
+	records, err := ReadTranscript(&transcript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay, err := NewReplay(records, "incident")
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine = NewEngine(&unreachableLLM{t: t}, 1, 1).WithReplay(replay).WithReflection(ReflectionConfig{Critic: &unreachableLLM{t: t}})
+	res := runSingleTask(t, engine, replay.NewTask())
+	if res.Error != nil || res.Output != "final" || len(res.Reflections) != 2 {
+		t.Fatalf("replay diverged: %q (err %v), verdicts %+v", res.Output, res.Error, res.Reflections)
+	}
+}
+
+func TestReflection_unreadableVerdictIsNotApproval(t *testing.T) {
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{{Content: "draft"}, {Content: "final"}}}
+	critic := &scriptedLLM{responses: []llm.LLMResponse{
+		{Content: "Looks fine to me!"},
+		{Content: `{"approved":false,"feedback":"expand"}`},
+		{Content: `{"approved":true}`},
+	}}
+	engine := NewEngine(adapter, 1, 1).WithReflection(ReflectionConfig{Critic: critic, MaxRounds: 3})
+
+	res := runSingleTask(t, engine, &Task{ID: "garbled", Input: "go"})
+	if res.Error != nil || res.Output != "final" {
+		t.Fatalf("an unreadable verdict must be re-asked, not approve the draft: %q (err %v)", res.Output, res.Error)
+	}
+	if len(res.Reflections) != 3 || !res.Reflections[0].Inconclusive || res.Reflections[0].Approved {
+		t.Errorf("unexpected verdicts %+v", res.Reflections)
+	}
+
+	adapter = &scriptedLLM{responses: []llm.LLMResponse{{Content: "draft"}}}
+	critic = &scriptedLLM{responses: []llm.LLMResponse{{Content: "???"}}}
+	engine = NewEngine(adapter, 1, 1).WithReflection(ReflectionConfig{Critic: critic, MaxRounds: 1})
+	res = runSingleTask(t, engine, &Task{ID: "garbled-last", Input: "go"})
+	if res.Error != nil || res.Output != "draft" || len(res.Reflections) != 1 || res.Reflections[0].Approved {
+		t.Errorf("an unreadable last verdict returns the answer unapproved, got %q %+v (err %v)", res.Output, res.Reflections, res.Error)
+	}
+}
@@ -0,0 +1,204 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/fzihak/aethercore/core/llm"
+	"github.com/fzihak/aethercore/core/security"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+var (
+	ErrTranscriptTaskNotFound = errors.New("task not found in transcript")
+	ErrReplayDiverged         = errors.New("replay diverged from transcript")
+)
+
+// ReadTranscript decodes a JSONL transcript.
+func ReadTranscript(r io.Reader) ([]TranscriptRecord, error) {
+	var records []TranscriptRecord
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var rec TranscriptRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("transcript_line_%d: %w", line, err)
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("transcript_read_failed: %w", err)
+	}
+	return records, nil
+}
+
+// Replay holds one task's recorded LLM responses, tool results and guard
+// verdicts. An Engine configured WithReplay feeds them back through the ReAct
+// loop instead of calling the model or executing tools, so a recorded
+// incident re-runs deterministically.
+type Replay struct {
+	Task   JournaledTask
+	Output string // recorded final output
+	Error  string // recorded final error, if any
+
+	inputGuard *GuardVerdict
+
+	mu    sync.Mutex
+	llm   []TranscriptRecord
+	next  int
+	tools map[string][]TranscriptRecord
+}
+
+// LoadReplay reads the transcript at path and prepares taskID for replay.
+func LoadReplay(path, taskID string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("transcript_open_failed: %w", err)
+	}
+	defer f.Close()
+
+	records, err := ReadTranscript(f)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplay(records, taskID)
+}
+
+// NewReplay prepares taskID's records for replay.
+func NewReplay(records []TranscriptRecord, taskID string) (*Replay, error) {
+	r := &Replay{tools: make(map[string][]TranscriptRecord)}
+	found := false
+	for i := range records {
+		rec := &records[i]
+		if rec.TaskID != taskID {
+			continue
+		}
+		switch rec.Type {
+		case TranscriptTask:
+			if rec.Task != nil {
+				r.Task = *rec.Task
+				found = true
+			}
+		case TranscriptGuard:
+			if rec.Guard != nil && rec.Guard.Stage == GuardStageInput && r.inputGuard == nil {
+				r.inputGuard = rec.Guard
+			}
+		case TranscriptLLM:
+			r.llm = append(r.llm, *rec)
+		case TranscriptTool:
+			if rec.ToolCall != nil && rec.ToolResult != nil {
+				key := replayToolKey(rec.Iteration, rec.ToolCall)
+				r.tools[key] = append(r.tools[key], *rec)
+			}
+		case TranscriptResult:
+			r.Output = rec.Output
+			r.Error = rec.Error
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrTranscriptTaskNotFound, taskID)
+	}
+	return r, nil
+}
+
+// NewTask builds a fresh Task equivalent to the recorded one.
+func (r *Replay) NewTask() *Task {
+	return &Task{
+		ID:        r.Task.ID,
+		System:    r.Task.System,
+		Input:     r.Task.Input,
+		CreatedAt: r.Task.CreatedAt,
+		Options:   r.Task.Options,
+	}
+}
+
+// WithReplay switches the engine into replay mode: LLM calls return the
+// recorded responses in order, tool calls return their recorded results, and
+// the input guard verdict is taken from the transcript. Any request the
+// transcript cannot answer fails the task with ErrReplayDiverged. The LLM
+// verifier is dropped from the guard so that replay never contacts a model.
+func (e *Engine) WithReplay(r *Replay) *Engine {
+	e.replay = r
+	e.adapter = &replayAdapter{replay: r}
+	e.guard = security.NewOrchestratorGuard(security.NewRegexScanner(), security.NewSemanticAnalyzer())
+	return e
+}
+
+// replayToolKey identifies a tool call within a transcript.
+func replayToolKey(iteration int, call *llm.ToolCall) string {
+	return fmt.Sprintf("%d\x00%s\x00%s\x00%s", iteration, call.ID, call.Name, call.Arguments)
+}
+
+// nextResponse returns the next recorded LLM response.
+func (r *Replay) nextResponse() (llm.LLMResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.next >= len(r.llm) {
+		return llm.LLMResponse{}, fmt.Errorf("%w: unexpected LLM call #%d", ErrReplayDiverged, r.next+1)
+	}
+	rec := r.llm[r.next]
+	r.next++
+	if err := rec.err(); err != nil {
+		return llm.LLMResponse{}, err
+	}
+	if rec.Response == nil {
+		return llm.LLMResponse{}, nil
+	}
+	return *rec.Response, nil
+}
+
+// toolResult returns the recorded result of a call, together with the
+// original execution error so that batch semantics (such as aborting on a
+// security violation) are reproduced.
+func (r *Replay) toolResult(iteration int, call llm.ToolCall) (llm.ToolResultMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := replayToolKey(iteration, &call)
+	queue := r.tools[key]
+	if len(queue) == 0 {
+		err := fmt.Errorf("%w: unexpected call to %s in iteration %d", ErrReplayDiverged, call.Name, iteration)
+		return llm.ToolResultMessage{ToolCallID: call.ID, Content: toolErrorContent(err), IsError: true}, err
+	}
+	rec := queue[0]
+	r.tools[key] = queue[1:]
+	return *rec.ToolResult, rec.err()
+}
+
+// inputVerdict returns the recorded input guard verdict, if any. It is safe
+// to call on a nil Replay.
+func (r *Replay) inputVerdict() (security.GuardResult, bool) {
+	if r == nil || r.inputGuard == nil {
+		return security.GuardResult{}, false
+	}
+	return security.GuardResult{
+		IsSafe:     r.inputGuard.Safe,
+		Confidence: r.inputGuard.Confidence,
+		Violations: r.inputGuard.Violations,
+	}, true
+}
+
+// replayAdapter serves recorded responses in place of a live model.
+type replayAdapter struct {
+	replay *Replay
+}
+
+func (a *replayAdapter) Name() string { return "replay" }
+
+func (a *replayAdapter) Generate(_ context.Context, _, _ string) (string, error) {
+	resp, err := a.replay.nextResponse()
+	return resp.Content, err
+}
+
+func (a *replayAdapter) GenerateWithTools(_ context.Context, _ []llm.Message, _ []llm.ToolManifest) (llm.LLMResponse, error) {
+	return a.replay.nextResponse()
+}
@@ -0,0 +1,150 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+const (
+	defaultRetryBackoff    = 500 * time.Millisecond
+	defaultMaxRetryBackoff = 30 * time.Second
+	defaultRetryMultiplier = 2
+)
+
+// RetryPolicy re-runs a task that failed with a transient error: an
+// unreachable, overloaded or circuit-broken LLM provider, an unavailable
+// sandbox, or a call that timed out. All attempts share the task's Timeout and
+// Deadline; a task that runs out of time fails with ErrTaskTimeout.
+// Security violations, cancellations, budget and validation failures are never
+// retried. Each attempt starts from the task's input, so tools may run again.
+// The zero value disables retries.
+type RetryPolicy struct {
+	// MaxAttempts counts the first run; values below 2 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry. Zero means defaultRetryBackoff.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between attempts. Zero means defaultMaxRetryBackoff.
+	MaxBackoff time.Duration
+	// Multiplier grows the wait after each retry. Zero means defaultRetryMultiplier.
+	Multiplier float64
+}
+
+// DefaultRetryPolicy makes three attempts with exponential backoff from 500ms.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 3}
+}
+
+// backoff returns the wait after the given failed attempt (1-based).
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d, limit, mult := p.InitialBackoff, p.MaxBackoff, p.Multiplier
+	if d <= 0 {
+		d = defaultRetryBackoff
+	}
+	if limit <= 0 {
+		limit = defaultMaxRetryBackoff
+	}
+	if mult <= 0 {
+		mult = defaultRetryMultiplier
+	}
+	for range attempt - 1 {
+		d = time.Duration(float64(d) * mult)
+		if d >= limit {
+			return limit
+		}
+	}
+	return min(d, limit)
+}
+
+// retryDelay is the backoff after attempt, lengthened to the provider's
+// Retry-After when that is longer, still within MaxBackoff.
+func (p *RetryPolicy) retryDelay(attempt int, err error) time.Duration {
+	d := p.backoff(attempt)
+	var statusErr *llm.StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > d {
+		limit := p.MaxBackoff
+		if limit <= 0 {
+			limit = defaultMaxRetryBackoff
+		}
+		d = min(statusErr.RetryAfter, limit)
+	}
+	return d
+}
+
+// isTransient reports whether err is worth retrying.
+func isTransient(err error) bool {
+	if err == nil || errors.Is(err, ErrTaskTimeout) || strings.Contains(err.Error(), "security_violation") {
+		return false
+	}
+	var statusErr *llm.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Temporary()
+	}
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, ErrSandboxUnavailable) ||
+		errors.Is(err, llm.ErrCircuitOpen) ||
+		errors.Is(err, llm.ErrNoHealthyProvider) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.As(err, &netErr)
+}
+
+// executeWithRetry runs the task under its retry policy. Backoff waits hold
+// the worker and end early if the task is cancelled or the engine stops.
+// Each attempt's meter is seeded with the usage so far, so Result.Usage and
+// the token budget cover all attempts.
+func (e *Engine) executeWithRetry(t *Task, res *Result) (string, error) {
+	ctx, cancel := t.Options.bind(t.context())
+	defer cancel()
+
+	policy := &t.Options.Retry
+	for attempt := 1; ; attempt++ {
+		res.Attempts = attempt
+		res.JSON, res.Steps, res.Reflections = nil, nil, nil
+		t.setStatus(TaskRunning, 0, "")
+		out, err := e.executeEphemeral(ctx, t, res)
+		t.resume = nil // later attempts start afresh
+
+		if err != nil && errors.Is(context.Cause(ctx), ErrTaskTimeout) {
+			return out, fmt.Errorf("%w: %w", ErrTaskTimeout, err)
+		}
+		if err == nil || attempt >= policy.MaxAttempts || !isTransient(err) || ctx.Err() != nil {
+			return out, err
+		}
+
+		delay := policy.retryDelay(attempt, err)
+		WithTask(t.context(), t.ID).Warn("task_retry_scheduled",
+			slog.Int("attempt", attempt),
+			slog.Duration("backoff", delay),
+			slog.String("error", err.Error()),
+		)
+		e.logAudit(t.context(), t.ID+"-retry", "AUDIT_TASK_RETRY", "engine", map[string]interface{}{
+			"task_id": t.ID, "attempt": attempt, "error": err.Error(),
+		})
+		t.setStatus(TaskRetrying, 0, "")
+		t.emit(ctx.Done(), TaskEvent{Type: EventRetry, Attempt: attempt + 1, Err: err})
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			if errors.Is(context.Cause(ctx), ErrTaskTimeout) {
+				return "", fmt.Errorf("%w: %w", ErrTaskTimeout, err)
+			}
+			return "", ctx.Err()
+		case <-e.quit:
+			timer.Stop()
+			return "", err
+		}
+	}
+}
@@ -0,0 +1,466 @@
+// Package schema implements a dependency-free validator for the commonly used
+// subset of JSON Schema draft-07: type, properties, required,
+// additionalProperties, enum, const, items, minItems/maxItems,
+// minLength/maxLength, minimum/maximum, exclusiveMinimum/exclusiveMaximum and
+// pattern. Unsupported keywords are ignored, as the specification requires for
+// unknown keywords.
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+var (
+	ErrInvalidSchema = errors.New("invalid json schema")
+	ErrInvalidJSON   = errors.New("document is not valid json")
+)
+
+// Schema is a compiled JSON Schema node.
+type Schema struct {
+	Types                []string
+	Properties           map[string]*Schema
+	Required             []string
+	AdditionalProperties *Schema // nil allows anything; see allowAdditional
+	Enum                 []any
+	Const                any
+	hasConst             bool
+	Items                *Schema
+	MinItems, MaxItems   *int
+	MinLength, MaxLength *int
+	Minimum, Maximum     *float64
+	ExclusiveMinimum     *float64
+	ExclusiveMaximum     *float64
+	Pattern              *regexp.Regexp
+
+	allowAdditional bool
+}
+
+// rawSchema mirrors the wire format before compilation.
+type rawSchema struct {
+	Type                 json.RawMessage            `json:"type"`
+	Properties           map[string]json.RawMessage `json:"properties"`
+	Required             []string                   `json:"required"`
+	AdditionalProperties json.RawMessage            `json:"additionalProperties"`
+	Enum                 []json.RawMessage          `json:"enum"`
+	Const                json.RawMessage            `json:"const"`
+	Items                json.RawMessage            `json:"items"`
+	MinItems             *int                       `json:"minItems"`
+	MaxItems             *int                       `json:"maxItems"`
+	MinLength            *int                       `json:"minLength"`
+	MaxLength            *int                       `json:"maxLength"`
+	Minimum              *float64                   `json:"minimum"`
+	Maximum              *float64                   `json:"maximum"`
+	ExclusiveMinimum     *float64                   `json:"exclusiveMinimum"`
+	ExclusiveMaximum     *float64                   `json:"exclusiveMaximum"`
+	Pattern              string                     `json:"pattern"`
+}
+
+// Compile parses a JSON Schema document. An empty document compiles to a
+// schema that accepts any value.
+func Compile(raw json.RawMessage) (*Schema, error) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return &Schema{allowAdditional: true}, nil
+	}
+	return compileAt(raw, "$")
+}
+
+func compileAt(raw json.RawMessage, path string) (*Schema, error) {
+	trimmed := bytes.TrimSpace(raw)
+	// Draft-07 boolean schemas: true accepts everything, false nothing.
+	switch string(trimmed) {
+	case "true":
+		return &Schema{allowAdditional: true}, nil
+	case "false":
+		return &Schema{Types: []string{}}, nil
+	}
+
+	var r rawSchema
+	if err := json.Unmarshal(trimmed, &r); err != nil {
+		return nil, fmt.Errorf("%w at %s: %w", ErrInvalidSchema, path, err)
+	}
+
+	s := &Schema{
+		Required:         r.Required,
+		MinItems:         r.MinItems,
+		MaxItems:         r.MaxItems,
+		MinLength:        r.MinLength,
+		MaxLength:        r.MaxLength,
+		Minimum:          r.Minimum,
+		Maximum:          r.Maximum,
+		ExclusiveMinimum: r.ExclusiveMinimum,
+		ExclusiveMaximum: r.ExclusiveMaximum,
+		allowAdditional:  true,
+	}
+
+	if err := s.compileType(r.Type, path); err != nil {
+		return nil, err
+	}
+	if err := s.compileChildren(&r, path); err != nil {
+		return nil, err
+	}
+	if err := s.compileValues(&r, path); err != nil {
+		return nil, err
+	}
+
+	if r.Pattern != "" {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w at %s: pattern: %w", ErrInvalidSchema, path, err)
+		}
+		s.Pattern = re
+	}
+	return s, nil
+}
+
+func (s *Schema) compileType(raw json.RawMessage, path string) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		s.Types = []string{single}
+	} else if err := json.Unmarshal(raw, &s.Types); err != nil {
+		return fmt.Errorf("%w at %s: type must be a string or array of strings", ErrInvalidSchema, path)
+	}
+	for _, t := range s.Types {
+		switch t {
+		case "object", "array", "string", "number", "integer", "boolean", "null":
+		default:
+			return fmt.Errorf("%w at %s: unknown type %q", ErrInvalidSchema, path, t)
+		}
+	}
+	return nil
+}
+
+func (s *Schema) compileChildren(r *rawSchema, path string) error {
+	if len(r.Properties) > 0 {
+		s.Properties = make(map[string]*Schema, len(r.Properties))
+		for name, sub := range r.Properties {
+			child, err := compileAt(sub, path+"."+name)
+			if err != nil {
+				return err
+			}
+			s.Properties[name] = child
+		}
+	}
+
+	if len(r.AdditionalProperties) > 0 {
+		switch string(bytes.TrimSpace(r.AdditionalProperties)) {
+		case "true":
+		case "false":
+			s.allowAdditional = false
+		default:
+			child, err := compileAt(r.AdditionalProperties, path+".additionalProperties")
+			if err != nil {
+				return err
+			}
+			s.AdditionalProperties = child
+		}
+	}
+
+	if len(r.Items) > 0 {
+		child, err := compileAt(r.Items, path+"[]")
+		if err != nil {
+			return err
+		}
+		s.Items = child
+	}
+	return nil
+}
+
+func (s *Schema) compileValues(r *rawSchema, path string) error {
+	for _, e := range r.Enum {
+		v, err := decode(e)
+		if err != nil {
+			return fmt.Errorf("%w at %s: enum: %w", ErrInvalidSchema, path, err)
+		}
+		s.Enum = append(s.Enum, v)
+	}
+	if len(r.Const) > 0 {
+		v, err := decode(r.Const)
+		if err != nil {
+			return fmt.Errorf("%w at %s: const: %w", ErrInvalidSchema, path, err)
+		}
+		s.Const = v
+		s.hasConst = true
+	}
+	return nil
+}
+
+// Violation describes a single way in which a document fails its schema.
+type Violation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValidationError collects every violation found in a document.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, v.Path+": "+v.Message)
+	}
+	return "schema validation failed: " + strings.Join(parts, "; ")
+}
+
+// Validate checks a raw JSON document against the schema. It returns
+// ErrInvalidJSON for malformed input and *ValidationError for violations.
+func (s *Schema) Validate(doc []byte) error {
+	v, err := decode(doc)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidJSON, err)
+	}
+	return s.ValidateValue(v)
+}
+
+// ValidateValue checks an already-decoded value. Numbers may be float64,
+// json.Number or any Go integer type.
+func (s *Schema) ValidateValue(v any) error {
+	var out []Violation
+	s.check(v, "$", &out)
+	if len(out) == 0 {
+		return nil
+	}
+	return &ValidationError{Violations: out}
+}
+
+// decode parses JSON preserving number precision.
+func decode(doc []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, errors.New("trailing data after json value")
+	}
+	return v, nil
+}
+
+func (s *Schema) check(v any, path string, out *[]Violation) {
+	if s.Types != nil && len(s.Types) == 0 {
+		*out = append(*out, Violation{Path: path, Message: "no value is allowed here"})
+		return
+	}
+	if s.Types != nil && !s.typeMatches(v) {
+		*out = append(*out, Violation{Path: path, Message: fmt.Sprintf("expected %s, got %s", strings.Join(s.Types, " or "), typeOf(v))})
+		return
+	}
+	if len(s.Enum) > 0 && !s.inEnum(v) {
+		*out = append(*out, Violation{Path: path, Message: "value is not one of the allowed enum values"})
+	}
+	if s.hasConst && !equal(v, s.Const) {
+		*out = append(*out, Violation{Path: path, Message: "value does not match const"})
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		s.checkObject(val, path, out)
+	case []any:
+		s.checkArray(val, path, out)
+	case string:
+		s.checkString(val, path, out)
+	default:
+		if f, ok := toFloat(v); ok {
+			s.checkNumber(f, path, out)
+		}
+	}
+}
+
+func (s *Schema) checkObject(obj map[string]any, path string, out *[]Violation) {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			*out = append(*out, Violation{Path: path + "." + name, Message: "is required"})
+		}
+	}
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys) // deterministic violation order
+
+	for _, k := range keys {
+		childPath := path + "." + k
+		if prop, ok := s.Properties[k]; ok {
+			prop.check(obj[k], childPath, out)
+			continue
+		}
+		if s.AdditionalProperties != nil {
+			s.AdditionalProperties.check(obj[k], childPath, out)
+		} else if !s.allowAdditional {
+			*out = append(*out, Violation{Path: childPath, Message: "additional property is not allowed"})
+		}
+	}
+}
+
+func (s *Schema) checkArray(arr []any, path string, out *[]Violation) {
+	if s.MinItems != nil && len(arr) < *s.MinItems {
+		*out = append(*out, Violation{Path: path, Message: fmt.Sprintf("must contain at least %d items", *s.MinItems)})
+	}
+	if s.MaxItems != nil && len(arr) > *s.MaxItems {
+		*out = append(*out, Violation{Path: path, Message: fmt.Sprintf("must contain at most %d items", *s.MaxItems)})
+	}
+	if s.Items != nil {
+		for i, item := range arr {
+			s.Items.check(item, fmt.Sprintf("%s[%d]", path, i), out)
+		}
+	}
+}
+
+func (s *Schema) checkString(str, path string, out *[]Violation) {
+	n := utf8.RuneCountInString(str)
+	if s.MinLength != nil && n < *s.MinLength {
+		*out = append(*out, Violation{Path: path, Message: fmt.Sprintf("must be at least %d characters", *s.MinLength)})
+	}
+	if s.MaxLength != nil && n > *s.MaxLength {
+		*out = append(*out, Violation{Path: path, Message: fmt.Sprintf("must be at most %d characters", *s.MaxLength)})
+	}
+	if s.Pattern != nil && !s.Pattern.MatchString(str) {
+		*out = append(*out, Violation{Path: path, Message: fmt.Sprintf("does not match pattern %q", s.Pattern.String())})
+	}
+}
+
+func (s *Schema) checkNumber(f float64, path string, out *[]Violation) {
+	if s.Minimum != nil && f < *s.Minimum {
+		*out = append(*out, Violation{Path: path, Message: fmt.Sprintf("must be >= %v", *s.Minimum)})
+	}
+	if s.Maximum != nil && f > *s.Maximum {
+		*out = append(*out, Violation{Path: path, Message: fmt.Sprintf("must be <= %v", *s.Maximum)})
+	}
+	if s.ExclusiveMinimum != nil && f <= *s.ExclusiveMinimum {
+		*out = append(*out, Violation{Path: path, Message: fmt.Sprintf("must be > %v", *s.ExclusiveMinimum)})
+	}
+	if s.ExclusiveMaximum != nil && f >= *s.ExclusiveMaximum {
+		*out = append(*out, Violation{Path: path, Message: fmt.Sprintf("must be < %v", *s.ExclusiveMaximum)})
+	}
+}
+
+func (s *Schema) typeMatches(v any) bool {
+	actual := typeOf(v)
+	for _, t := range s.Types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Schema) inEnum(v any) bool {
+	for _, e := range s.Enum {
+		if equal(v, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// typeOf returns the JSON Schema type name of a decoded value.
+func typeOf(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		f, ok := toFloat(val)
+		if !ok {
+			return fmt.Sprintf("%T", v)
+		}
+		if f == math.Trunc(f) && !math.IsInf(f, 0) {
+			return "integer"
+		}
+		return "number"
+	}
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uintptr:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// equal compares two decoded JSON values structurally, treating numbers by value.
+func equal(a, b any) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, x := range av {
+			y, ok := bv[k]
+			if !ok || !equal(x, y) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !equal(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
@@ -0,0 +1,164 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func mustCompile(t *testing.T, raw string) *Schema {
+	t.Helper()
+	s, err := Compile(json.RawMessage(raw))
+	if err != nil {
+		t.Fatalf("Compile(%s): %v", raw, err)
+	}
+	return s
+}
+
+func violations(t *testing.T, err error) []Violation {
+	t.Helper()
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("want *ValidationError, got %v", err)
+	}
+	return ve.Violations
+}
+
+const searchSchema = `{
+	"type": "object",
+	"properties": {
+		"query": {"type": "string", "minLength": 1, "maxLength": 20, "pattern": "^[a-z ]+$"},
+		"limit": {"type": "integer", "minimum": 1, "maximum": 50},
+		"mode":  {"enum": ["fast", "deep"]},
+		"tags":  {"type": "array", "items": {"type": "string"}, "maxItems": 2}
+	},
+	"required": ["query"],
+	"additionalProperties": false
+}`
+
+func TestValidate_valid(t *testing.T) {
+	s := mustCompile(t, searchSchema)
+	if err := s.Validate([]byte(`{"query":"go generics","limit":10,"mode":"deep","tags":["a"]}`)); err != nil {
+		t.Fatalf("expected valid document, got %v", err)
+	}
+}
+
+func TestValidate_collectsAllViolations(t *testing.T) {
+	s := mustCompile(t, searchSchema)
+	err := s.Validate([]byte(`{"limit":2.5,"mode":"slow","tags":["a",1,"c"],"extra":true}`))
+	got := violations(t, err)
+
+	want := map[string]string{
+		"$.query":   "is required",
+		"$.limit":   "expected integer",
+		"$.mode":    "enum",
+		"$.tags":    "at most 2 items",
+		"$.tags[1]": "expected string",
+		"$.extra":   "additional property",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("want %d violations, got %d: %+v", len(want), len(got), got)
+	}
+	for _, v := range got {
+		frag, ok := want[v.Path]
+		if !ok || !strings.Contains(v.Message, frag) {
+			t.Errorf("unexpected violation %+v", v)
+		}
+	}
+}
+
+func TestValidate_stringAndNumberBounds(t *testing.T) {
+	s := mustCompile(t, searchSchema)
+	got := violations(t, s.Validate([]byte(`{"query":"UPPER","limit":0}`)))
+	if len(got) != 2 {
+		t.Fatalf("want pattern + minimum violations, got %+v", got)
+	}
+
+	got = violations(t, s.Validate([]byte(`{"query":"","limit":51}`)))
+	if len(got) != 3 {
+		t.Fatalf("want minLength + pattern + maximum violations, got %+v", got)
+	}
+}
+
+func TestValidate_exclusiveBoundsAndConst(t *testing.T) {
+	s := mustCompile(t, `{"type":"number","exclusiveMinimum":0,"exclusiveMaximum":1}`)
+	if err := s.Validate([]byte(`0.5`)); err != nil {
+		t.Errorf("0.5 should be valid: %v", err)
+	}
+	if err := s.Validate([]byte(`1`)); err == nil {
+		t.Error("1 should violate exclusiveMaximum")
+	}
+
+	c := mustCompile(t, `{"const":{"v":1}}`)
+	if err := c.Validate([]byte(`{"v":1.0}`)); err != nil {
+		t.Errorf("numerically equal const should match: %v", err)
+	}
+	if err := c.Validate([]byte(`{"v":2}`)); err == nil {
+		t.Error("different const should fail")
+	}
+}
+
+func TestValidate_typeUnionAndBooleanSchemas(t *testing.T) {
+	s := mustCompile(t, `{"type":["string","null"]}`)
+	if err := s.Validate([]byte(`null`)); err != nil {
+		t.Errorf("null should match union: %v", err)
+	}
+	if err := s.Validate([]byte(`3`)); err == nil {
+		t.Error("number should not match string|null")
+	}
+
+	if err := mustCompile(t, `true`).Validate([]byte(`{"any":"thing"}`)); err != nil {
+		t.Errorf("true schema accepts everything: %v", err)
+	}
+	if err := mustCompile(t, `false`).Validate([]byte(`1`)); err == nil {
+		t.Error("false schema rejects everything")
+	}
+	if err := mustCompile(t, ``).Validate([]byte(`[1,2]`)); err != nil {
+		t.Errorf("empty schema accepts everything: %v", err)
+	}
+}
+
+func TestValidate_additionalPropertiesSchema(t *testing.T) {
+	s := mustCompile(t, `{"type":"object","additionalProperties":{"type":"integer"}}`)
+	if err := s.Validate([]byte(`{"a":1,"b":2}`)); err != nil {
+		t.Errorf("integers allowed: %v", err)
+	}
+	got := violations(t, s.Validate([]byte(`{"a":"x"}`)))
+	if got[0].Path != "$.a" {
+		t.Errorf("unexpected path %q", got[0].Path)
+	}
+}
+
+func TestValidate_malformedDocument(t *testing.T) {
+	s := mustCompile(t, searchSchema)
+	if err := s.Validate([]byte(`{"query":`)); !errors.Is(err, ErrInvalidJSON) {
+		t.Fatalf("want ErrInvalidJSON, got %v", err)
+	}
+}
+
+func TestCompile_rejectsInvalidSchemas(t *testing.T) {
+	for _, raw := range []string{
+		`{"type":"strng"}`,
+		`{"type":7}`,
+		`{"pattern":"("}`,
+		`{"properties":{"a":{"type":"nope"}}}`,
+		`not json`,
+	} {
+		if _, err := Compile(json.RawMessage(raw)); !errors.Is(err, ErrInvalidSchema) {
+			t.Errorf("Compile(%s): want ErrInvalidSchema, got %v", raw, err)
+		}
+	}
+}
+
+func TestValidateValue_acceptsEveryGoIntegerType(t *testing.T) {
+	s := mustCompile(t, `{"type": "integer", "minimum": 1, "maximum": 50}`)
+	for _, v := range []any{int(7), int8(7), int16(7), int32(7), int64(7), uint(7), uint8(7), uint16(7), uint32(7), uint64(7), uintptr(7)} {
+		if err := s.ValidateValue(v); err != nil {
+			t.Errorf("%T: want valid, got %v", v, err)
+		}
+	}
+	if got := violations(t, s.ValidateValue(uint8(200))); len(got) != 1 {
+		t.Errorf("want maximum violation for uint8(200), got %+v", got)
+	}
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+// ErrEngineStopped is returned for submissions after Stop or Shutdown began.
+var ErrEngineStopped = errors.New("engine is shutting down")
+
+// errAbandoned is the cancellation cause of tasks cut off by a Shutdown
+// deadline. Such tasks are not journaled as completed.
+var errAbandoned = errors.New("task abandoned by shutdown")
+
+// ShutdownReport describes the tasks a Shutdown could not drain.
+type ShutdownReport struct {
+	// Abandoned lists the tasks still queued or running at the deadline, as
+	// they were when cancelled. With a journal attached they remain pending and
+	// are resubmitted by Recover on the next start.
+	Abandoned []TaskStatus
+}
+
+// Shutdown stops accepting work and waits for queued and running tasks to
+// finish and publish their results. If ctx expires first, the remaining tasks
+// are cancelled and reported as abandoned, and ctx's error is returned. Either
+// way the workers have exited and Results is closed on return.
+func (e *Engine) Shutdown(ctx context.Context) (ShutdownReport, error) {
+	e.queue.close()
+	var report ShutdownReport
+	err := e.queue.drain(ctx)
+	if err != nil {
+		report.Abandoned = e.tracker.active()
+		for _, st := range report.Abandoned {
+			if h, ok := e.tracker.get(st.TaskID); ok {
+				h.cancel(errAbandoned)
+			}
+		}
+		WithComponent("engine").Warn("shutdown_deadline_exceeded", slog.Int("abandoned", len(report.Abandoned)))
+	}
+	e.halt()
+	e.logAudit(context.Background(), "sys-shutdown", "AUDIT_ENGINE_SHUTDOWN", "system",
+		map[string]interface{}{"abandoned": len(report.Abandoned)})
+	return report, err
+}
+
+// SubmitWait enqueues a task, blocking while the queue or the task's tenant
+// quota is full instead of failing. ctx bounds the wait and, as with
+// SubmitContext, the task's execution.
+func (e *Engine) SubmitWait(ctx context.Context, t *Task) error {
+	return e.enqueue(ctx, t, true, true)
+}
+
+// halt stops the workers and closes the result queue. Workers blocked on
+// publishing a result nobody reads give up once quit is closed.
+func (e *Engine) halt() {
+	e.stopOnce.Do(func() {
+		e.stopWorkers()
+
+		// Signal all workers to terminate their loops
+		close(e.quit)
+
+		// Strictly block until every single ephemeral worker has returned
+		e.wg.Wait()
+
+		// Resolve tasks no worker will pick up now, so their results and
+		// streams are still delivered
+		e.abandonQueued()
+
+		// Only after all workers are dead is it safe to close the result queue
+		close(e.resultQueue)
+	})
+}
+
+// abandonQueued cancels every task still queued and processes it, which
+// publishes its Result and closes its stream without running it. With a
+// journal attached the tasks remain pending for Recover. Workers must have
+// exited.
+func (e *Engine) abandonQueued() {
+	for t := e.queue.pop(); t != nil; t = e.queue.pop() {
+		t.handle.cancel(errAbandoned)
+		e.process(-1, t)
+	}
+}
+
+// active returns the status of every task not yet in a terminal state.
+func (tr *taskTracker) active() []TaskStatus {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	var out []TaskStatus
+	for _, h := range tr.handles {
+		if st := h.snapshot(); !st.State.Terminal() {
+			out = append(out, st)
+		}
+	}
+	return out
+}
@@ -0,0 +1,164 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+func TestShutdown_drainsQueuedAndRunningTasks(t *testing.T) {
+	adapter := newBlockingLLM()
+	engine := NewEngine(adapter, 1, 4)
+	engine.Start()
+	for _, id := range []string{"a", "b", "c"} {
+		if err := engine.Submit(&Task{ID: id, Input: "go"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(adapter.release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	report, err := engine.Shutdown(ctx)
+	if err != nil || len(report.Abandoned) != 0 {
+		t.Fatalf("drain failed: %v, abandoned %+v", err, report.Abandoned)
+	}
+
+	done := 0
+	for res := range engine.Results() { // closed by Shutdown
+		if res.Error != nil {
+			t.Errorf("%s: %v", res.TaskID, res.Error)
+		}
+		done++
+	}
+	if done != 3 {
+		t.Errorf("want 3 results, got %d", done)
+	}
+	if err := engine.Submit(&Task{ID: "late", Input: "go"}); !errors.Is(err, ErrEngineStopped) {
+		t.Errorf("want ErrEngineStopped after shutdown, got %v", err)
+	}
+}
+
+func TestShutdown_deadlineCancelsAndReportsAbandoned(t *testing.T) {
+	adapter := newBlockingLLM()
+	engine := NewEngine(adapter, 1, 4)
+	engine.Start()
+	_ = engine.Submit(&Task{ID: "running", Input: "go"})
+	_ = engine.Submit(&Task{ID: "queued", Input: "go"})
+	<-adapter.calls
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	report, err := engine.Shutdown(ctx) // nobody reads Results; must not hang
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want deadline error, got %v", err)
+	}
+	states := map[string]TaskState{}
+	for _, st := range report.Abandoned {
+		states[st.TaskID] = st.State
+	}
+	if states["running"] != TaskRunning || states["queued"] != TaskQueued {
+		t.Errorf("unexpected abandoned tasks %+v", report.Abandoned)
+	}
+}
+
+func TestShutdown_deadlineResolvesQueuedTasks(t *testing.T) {
+	adapter := newBlockingLLM()
+	engine := NewEngine(adapter, 1, 4)
+	engine.Start()
+	_ = engine.Submit(&Task{ID: "running", Input: "go"})
+	events, err := engine.SubmitStream(&Task{ID: "queued", Input: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-adapter.calls
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := engine.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want deadline error, got %v", err)
+	}
+
+	got := collectEvents(t, events)
+	if len(got) != 1 || got[0].Type != EventError || !errors.Is(got[0].Err, ErrTaskCancelled) {
+		t.Fatalf("the queued task's stream should end with a cancellation, got %+v", got)
+	}
+	if st, err := engine.Status("queued"); err != nil || st.State != TaskCancelled {
+		t.Errorf("want the queued task cancelled, got %+v (%v)", st, err)
+	}
+	published := map[string]bool{}
+	for res := range engine.Results() {
+		published[res.TaskID] = true
+	}
+	if !published["queued"] {
+		t.Errorf("the queued task's result was not published, got %v", published)
+	}
+	if n := len(adapter.calls); n != 0 {
+		t.Errorf("the queued task must not run, got %d more LLM calls", n)
+	}
+}
+
+func TestShutdown_abandonedTasksRecoverFromJournal(t *testing.T) {
+	j := openTestJournal(t, filepath.Join(t.TempDir(), "tasks.journal"))
+	adapter := newBlockingLLM()
+	engine := NewEngine(adapter, 1, 4).WithJournal(j)
+	engine.Start()
+	_ = engine.Submit(&Task{ID: "running", Input: "go"})
+	_ = engine.Submit(&Task{ID: "queued", Input: "go"})
+	<-adapter.calls
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := engine.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want deadline error, got %v", err)
+	}
+
+	next := NewEngine(&scriptedLLM{responses: []llm.LLMResponse{{Content: "ok"}, {Content: "ok"}}}, 1, 4).WithJournal(j)
+	n, err := next.Recover()
+	if err != nil || n != 2 {
+		t.Fatalf("abandoned tasks must be resubmitted: n=%d err=%v", n, err)
+	}
+	next.Start()
+	defer next.Stop()
+	for range 2 {
+		if res := <-next.Results(); res.Error != nil {
+			t.Errorf("%s: %v", res.TaskID, res.Error)
+		}
+	}
+}
+
+func TestSubmitWait_blocksUntilSpace(t *testing.T) {
+	engine := NewEngine(&scriptedLLM{responses: []llm.LLMResponse{{Content: "ok"}}}, 1, 1)
+	defer engine.Stop()
+	if err := engine.Submit(&Task{ID: "first", Input: "go"}); err != nil {
+		t.Fatal(err)
+	}
+
+	short, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := engine.SubmitWait(short, &Task{ID: "impatient", Input: "go"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want deadline while the queue is full, got %v", err)
+	}
+
+	submitted := make(chan error, 1)
+	go func() { submitted <- engine.SubmitWait(context.Background(), &Task{ID: "patient", Input: "go"}) }()
+	select {
+	case err := <-submitted:
+		t.Fatalf("SubmitWait returned early: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	engine.Start()
+	if err := <-submitted; err != nil {
+		t.Fatalf("SubmitWait: %v", err)
+	}
+	for range 2 {
+		if res := <-engine.Results(); res.Error != nil {
+			t.Errorf("%s: %v", res.TaskID, res.Error)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+// ErrUnknownStrategy is returned for a task naming an unregistered strategy.
+var ErrUnknownStrategy = errors.New("unknown agent strategy")
+
+// Built-in strategy names for TaskOptions.Strategy.
+const (
+	StrategyReAct       = "react"
+	StrategyPlanExecute = "plan_execute"
+)
+
+// AgentStrategy drives a task from input to final answer using the engine
+// primitives exposed by AgentRun.
+type AgentStrategy interface {
+	Name() string
+	Run(ctx context.Context, run *AgentRun) (string, error)
+}
+
+// builtinStrategies returns the strategies every engine starts with.
+func builtinStrategies() map[string]AgentStrategy {
+	return map[string]AgentStrategy{
+		StrategyReAct:       reactStrategy{},
+		StrategyPlanExecute: planExecuteStrategy{},
+	}
+}
+
+// RegisterStrategy makes a strategy selectable by name via TaskOptions.Strategy.
+// Registering a built-in name replaces it. Call before Start.
+func (e *Engine) RegisterStrategy(s AgentStrategy) *Engine {
+	e.strategies[s.Name()] = s
+	return e
+}
+
+// strategyFor resolves a task's strategy; ReAct is the default.
+func (e *Engine) strategyFor(o *TaskOptions) (AgentStrategy, error) {
+	name := o.Strategy
+	if name == "" {
+		name = StrategyReAct
+	}
+	s, ok := e.strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownStrategy, name)
+	}
+	return s, nil
+}
+
+// AgentRun is one task's execution as seen by a strategy. Its methods apply
+// the engine's cross-cutting behaviour: context-window fitting, interceptors,
+// transcripts, token budgets, tool policies and journaling.
+type AgentRun struct {
+	Task  *Task
+	Tools []llm.ToolManifest // manifests the task may use
+
+	engine     *Engine
+	res        *Result
+	scope      *taskScope
+	structured *structuredOutput
+	iteration  int // of the latest Generate call
+}
+
+// Generate performs one LLM turn. Iteration numbers must be unique within the
+// run; they key the turn in events, transcripts and replay.
+func (r *AgentRun) Generate(ctx context.Context, iteration int, messages []llm.Message, tools []llm.ToolManifest) (llm.LLMResponse, error) {
+	e, t := r.engine, r.Task
+	r.iteration = iteration
+	t.setStatus(TaskRunning, iteration, "")
+	prompt := e.window.Fit(messages, t.Options.Model)
+	start := time.Now()
+	resp, err := e.generate(ctx, t, iteration, prompt, tools)
+	e.record(&TranscriptRecord{
+		Type: TranscriptLLM, TaskID: t.ID, Iteration: iteration, Messages: prompt, Response: &resp,
+		Error: errorString(err), ErrorKinds: errorKinds(err), DurationMs: time.Since(start).Milliseconds(),
+	})
+	if err != nil {
+		return resp, err
+	}
+	err = r.scope.meter.charge(resp.TokenUsage)
+	r.res.Usage = r.scope.meter.total()
+	return resp, err
+}
+
+// RunTools executes the tool calls of one model turn and returns their results in order.
+func (r *AgentRun) RunTools(ctx context.Context, iteration int, calls []llm.ToolCall) ([]llm.ToolResultMessage, error) {
+	return r.engine.runToolCalls(ctx, r.Task, iteration, calls)
+}
+
+// OutputFormat requests schema-constrained content for the final answer when
+// the task sets an OutputSchema and the turn cannot be suppressing tool calls.
+func (r *AgentRun) OutputFormat(ctx context.Context, toolsOffered bool) context.Context {
+	return r.structured.bind(ctx, toolsOffered)
+}
+
+// Finish validates a candidate final answer against the task's OutputSchema
+// and, when reflection is enabled, submits it to the critic together with
+// messages, the history that produced it. A non-nil retry message should be
+// appended (after the answer) and the model asked again; an error means the
+// retries are exhausted.
+func (r *AgentRun) Finish(ctx context.Context, messages []llm.Message, content string) (*llm.Message, error) {
+	doc, retry, err := r.structured.accept(content)
+	if err != nil || retry != nil {
+		return retry, err
+	}
+	if retry, err := r.reflect(ctx, messages, content); err != nil || retry != nil {
+		return retry, err
+	}
+	r.res.JSON = doc
+	return nil, nil
+}
+
+// Checkpoint journals the history after a completed iteration so that the
+// task can resume there after a crash.
+func (r *AgentRun) Checkpoint(iteration int, messages []llm.Message) {
+	r.checkpoint(&JournalEntry{Iteration: iteration, Messages: messages})
+}
+
+// CheckpointSteps journals plan progress after a completed step or (re-)plan,
+// so that a recovered task continues with the remaining steps.
+func (r *AgentRun) CheckpointSteps(iteration int, steps []StepStatus) {
+	r.checkpoint(&JournalEntry{Iteration: iteration, Steps: steps})
+}
+
+func (r *AgentRun) checkpoint(entry *JournalEntry) {
+	if r.Task.parent != "" { // sub-agents are re-run by their parent's recovery
+		return
+	}
+	entry.Type, entry.TaskID, entry.Usage = JournalIteration, r.Task.ID, r.res.Usage
+	r.engine.journalAppend(entry)
+}
+
+// SetSteps publishes per-step progress on the task's Result.
+func (r *AgentRun) SetSteps(steps []StepStatus) {
+	r.res.Steps = append(r.res.Steps[:0], steps...)
+}
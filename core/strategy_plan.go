@@ -0,0 +1,324 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fzihak/aethercore/core/llm"
+	"github.com/fzihak/aethercore/core/schema"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+// ErrPlanFailed is returned when a plan cannot be completed within the re-plan limit.
+var ErrPlanFailed = errors.New("plan could not be completed")
+
+const (
+	// maxPlanSteps bounds the number of steps in a single plan.
+	maxPlanSteps = 10
+	// maxReplans bounds how often a failed step may trigger a new plan.
+	maxReplans = 2
+	// planFormatRetries bounds re-prompts for malformed plans and step reports.
+	planFormatRetries = 2
+)
+
+// StepState is the progress of one plan step.
+type StepState string
+
+const (
+	StepPending StepState = "pending"
+	StepDone    StepState = "done"
+	StepFailed  StepState = "failed"
+	StepSkipped StepState = "skipped" // superseded by a re-plan
+)
+
+// StepStatus reports one plan step in Result.Steps.
+type StepStatus struct {
+	Index       int
+	Description string
+	State       StepState
+	Result      string
+	Error       string
+}
+
+var (
+	planSchema = json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"steps": {
+				"type": "array", "minItems": 1, "maxItems": 10,
+				"items": {"type": "object", "properties": {"description": {"type": "string", "minLength": 1}}, "required": ["description"]}
+			}
+		},
+		"required": ["steps"]
+	}`)
+	stepReportSchema = json.RawMessage(`{
+		"type": "object",
+		"properties": {"status": {"enum": ["done", "failed"]}, "result": {"type": "string"}},
+		"required": ["status", "result"]
+	}`)
+
+	compiledPlanSchema       = mustCompileSchema(planSchema)
+	compiledStepReportSchema = mustCompileSchema(stepReportSchema)
+)
+
+func mustCompileSchema(raw json.RawMessage) *schema.Schema {
+	s, err := schema.Compile(raw)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// planExecuteStrategy first asks the model for an explicit JSON step plan,
+// then executes each step in its own short tool-using loop, re-planning the
+// remaining work when a step fails. MaxIterations bounds the turns per step.
+// The plan is checkpointed after every (re-)plan and completed step, so a task
+// recovered from the journal continues with its remaining steps.
+type planExecuteStrategy struct{}
+
+func (planExecuteStrategy) Name() string { return StrategyPlanExecute }
+
+func (planExecuteStrategy) Run(ctx context.Context, run *AgentRun) (string, error) {
+	p := &planRun{run: run}
+	if r := run.Task.resume; r != nil && len(r.steps) > 0 {
+		p.restore(r)
+	} else if err := p.plan(ctx, ""); err != nil {
+		return "", err
+	}
+
+	for i := 0; i < len(p.steps); i++ {
+		if p.steps[i].State != StepPending {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		if err := p.execute(ctx, i); err != nil {
+			return "", err
+		}
+		if p.steps[i].State == StepDone {
+			p.checkpoint()
+			continue
+		}
+		if p.replans >= maxReplans {
+			return "", fmt.Errorf("%w: step %d failed after %d re-plans: %s", ErrPlanFailed, i+1, p.replans, p.steps[i].Error)
+		}
+		p.replans++
+		p.skipPending()
+		if err := p.plan(ctx, fmt.Sprintf("Step %d (%s) failed: %s", i+1, p.steps[i].Description, p.steps[i].Error)); err != nil {
+			return "", err
+		}
+	}
+	return p.synthesize(ctx)
+}
+
+// planRun is the mutable state of one plan-and-execute run.
+type planRun struct {
+	run     *AgentRun
+	steps   []StepStatus
+	turn    int // running LLM turn counter; unique iteration numbers
+	replans int
+}
+
+// nextTurn reserves an iteration number for an LLM call.
+func (p *planRun) nextTurn() int {
+	p.turn++
+	return p.turn - 1
+}
+
+// plan asks the model for (a revision of) the plan and appends its steps.
+func (p *planRun) plan(ctx context.Context, failure string) error {
+	t := p.run.Task
+	var b strings.Builder
+	fmt.Fprintf(&b, "Goal: %s\n", t.Input)
+	if len(p.steps) > 0 {
+		b.WriteString(p.progress())
+		fmt.Fprintf(&b, "%s\nProduce a revised plan covering only the remaining work.\n", failure)
+	}
+	b.WriteString(p.toolList())
+
+	system := t.systemPrompt() + "\n\nBefore acting, break the goal into a short sequence of concrete steps. " +
+		`Reply only with JSON of the form {"steps":[{"description":"..."}]}.`
+	doc, err := p.structured(ctx, system, b.String(), planSchema, compiledPlanSchema)
+	if err != nil {
+		return fmt.Errorf("planning: %w", err)
+	}
+
+	var plan struct {
+		Steps []struct {
+			Description string `json:"description"`
+		} `json:"steps"`
+	}
+	if err := json.Unmarshal(doc, &plan); err != nil {
+		return fmt.Errorf("planning: %w", err)
+	}
+	for _, s := range plan.Steps[:min(len(plan.Steps), maxPlanSteps)] {
+		p.steps = append(p.steps, StepStatus{Index: len(p.steps), Description: s.Description, State: StepPending})
+	}
+	p.run.SetSteps(p.steps)
+	p.checkpoint()
+	return nil
+}
+
+// restore continues a run from a journal checkpoint. Every failed step
+// triggered one re-plan, so the re-plan count is recovered from them.
+func (p *planRun) restore(r *resumeState) {
+	p.steps = append([]StepStatus(nil), r.steps...)
+	p.turn = r.iteration + 1
+	for i := range p.steps {
+		if p.steps[i].State == StepFailed {
+			p.replans++
+		}
+	}
+	p.run.SetSteps(p.steps)
+}
+
+// checkpoint journals the plan as of the last LLM turn.
+func (p *planRun) checkpoint() {
+	p.run.CheckpointSteps(p.turn-1, p.steps)
+}
+
+// execute runs step i in a bounded tool-using loop until the model reports
+// the step done or failed.
+func (p *planRun) execute(ctx context.Context, i int) error {
+	t, step := p.run.Task, &p.steps[i]
+	so := &structuredOutput{raw: stepReportSchema, schema: compiledStepReportSchema, retries: planFormatRetries}
+	messages := []llm.Message{
+		{Role: "system", Content: t.systemPrompt() + "\n\nYou are executing one step of a plan. Use tools as needed, then reply " +
+			`only with JSON {"status":"done"|"failed","result":"..."} describing the outcome of this step.`},
+		{Role: "user", Content: fmt.Sprintf("Goal: %s\n%sCurrent step %d: %s", t.Input, p.progress(), i+1, step.Description)},
+	}
+
+	for range t.Options.maxIterations() {
+		iteration := p.nextTurn()
+		resp, err := p.run.Generate(so.bind(ctx, len(p.run.Tools) > 0), iteration, messages, p.run.Tools)
+		if err != nil {
+			return fmt.Errorf("step_%d: %w", i+1, err)
+		}
+		if len(resp.ToolCalls) == 0 {
+			doc, retry, err := so.accept(resp.Content)
+			switch {
+			case err != nil:
+				p.finishStep(i, StepFailed, "", "invalid step report: "+err.Error())
+				return nil
+			case retry != nil:
+				messages = append(messages, llm.Message{Role: "assistant", Content: resp.Content}, *retry)
+				continue
+			}
+			var report struct{ Status, Result string }
+			_ = json.Unmarshal(doc, &report)
+			if report.Status == string(StepDone) {
+				p.finishStep(i, StepDone, report.Result, "")
+			} else {
+				p.finishStep(i, StepFailed, "", report.Result)
+			}
+			return nil
+		}
+
+		messages = append(messages, llm.Message{Role: "assistant", Content: resp.Content, ToolCalls: resp.ToolCalls})
+		results, err := p.run.RunTools(ctx, iteration, resp.ToolCalls)
+		if err != nil {
+			return fmt.Errorf("step_%d: %w", i+1, err)
+		}
+		messages = append(messages, llm.Message{Role: "tool", ToolResults: results})
+	}
+	p.finishStep(i, StepFailed, "", fmt.Sprintf("step did not complete within %d turns", t.Options.maxIterations()))
+	return nil
+}
+
+// synthesize asks the model for the final answer from the step results,
+// enforcing the task's OutputSchema if it has one. Finish bounds the retries.
+func (p *planRun) synthesize(ctx context.Context) (string, error) {
+	t := p.run.Task
+	messages := []llm.Message{
+		{Role: "system", Content: t.systemPrompt()},
+		{Role: "user", Content: fmt.Sprintf("Goal: %s\n%sAll steps are complete. Reply with the final answer to the goal.", t.Input, p.progress())},
+	}
+	for {
+		resp, err := p.run.Generate(p.run.OutputFormat(ctx, false), p.nextTurn(), messages, nil)
+		if err != nil {
+			return "", fmt.Errorf("synthesis: %w", err)
+		}
+		retry, err := p.run.Finish(ctx, messages, resp.Content)
+		if err != nil {
+			return "", err
+		}
+		if retry == nil {
+			return resp.Content, nil
+		}
+		messages = append(messages, llm.Message{Role: "assistant", Content: resp.Content}, *retry)
+	}
+}
+
+// structured performs a tool-less turn whose reply must match s, re-prompting
+// with the violations up to planFormatRetries times.
+func (p *planRun) structured(ctx context.Context, system, user string, raw json.RawMessage, s *schema.Schema) (json.RawMessage, error) {
+	so := &structuredOutput{raw: raw, schema: s, retries: planFormatRetries}
+	messages := []llm.Message{{Role: "system", Content: system}, {Role: "user", Content: user}}
+	for {
+		resp, err := p.run.Generate(so.bind(ctx, false), p.nextTurn(), messages, nil)
+		if err != nil {
+			return nil, err
+		}
+		doc, retry, err := so.accept(resp.Content)
+		if err != nil || retry == nil {
+			return doc, err
+		}
+		messages = append(messages, llm.Message{Role: "assistant", Content: resp.Content}, *retry)
+	}
+}
+
+func (p *planRun) finishStep(i int, state StepState, result, errMsg string) {
+	p.steps[i].State = state
+	p.steps[i].Result = result
+	p.steps[i].Error = errMsg
+	p.run.SetSteps(p.steps)
+}
+
+// skipPending marks every step not yet attempted as superseded.
+func (p *planRun) skipPending() {
+	for i := range p.steps {
+		if p.steps[i].State == StepPending {
+			p.steps[i].State = StepSkipped
+		}
+	}
+	p.run.SetSteps(p.steps)
+}
+
+// progress summarises the plan so far for the model.
+func (p *planRun) progress() string {
+	if len(p.steps) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Plan progress:\n")
+	for i := range p.steps {
+		s := &p.steps[i]
+		fmt.Fprintf(&b, "%d. [%s] %s", i+1, s.State, s.Description)
+		switch {
+		case s.Result != "":
+			fmt.Fprintf(&b, " => %s", s.Result)
+		case s.Error != "":
+			fmt.Fprintf(&b, " => error: %s", s.Error)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// toolList names the tools available to the plan's steps.
+func (p *planRun) toolList() string {
+	if len(p.run.Tools) == 0 {
+		return "No tools are available.\n"
+	}
+	var b strings.Builder
+	b.WriteString("Available tools:\n")
+	for _, m := range p.run.Tools {
+		fmt.Fprintf(&b, "- %s: %s\n", m.Name, m.Description)
+	}
+	return b.String()
+}
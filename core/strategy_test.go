@@ -0,0 +1,168 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+func TestPlanExecute_runsStepsAndReportsStatus(t *testing.T) {
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{
+		{Content: `{"steps":[{"description":"count things"},{"description":"summarise"}]}`},
+		{ToolCalls: []llm.ToolCall{{ID: "c1", Name: "count"}}},
+		{Content: `{"status":"done","result":"3 things"}`},
+		{Content: `{"status":"done","result":"three"}`},
+		{Content: "there are three things"},
+	}}
+	engine := NewEngine(adapter, 1, 1)
+	tool := &countingTool{name: "count"}
+	registerAll(t, engine, tool)
+
+	res := runSingleTask(t, engine, &Task{ID: "plan", Input: "how many?", Options: TaskOptions{Strategy: StrategyPlanExecute}})
+	if res.Error != nil || res.Output != "there are three things" {
+		t.Fatalf("unexpected result %q (err %v)", res.Output, res.Error)
+	}
+	if tool.count() != 1 {
+		t.Errorf("step should have run the tool once, got %d", tool.count())
+	}
+	if len(adapter.tools[0]) != 0 || len(adapter.tools[1]) != 1 {
+		t.Error("planner must not be offered tools; steps must be")
+	}
+	if len(adapter.formats[0]) == 0 {
+		t.Error("planner turn should request the plan schema")
+	}
+	if len(res.Steps) != 2 || res.Steps[0].State != StepDone || res.Steps[1].Result != "three" {
+		t.Errorf("unexpected steps %+v", res.Steps)
+	}
+	synthesis := adapter.requests[4]
+	if !strings.Contains(synthesis[1].Content, "3 things") {
+		t.Errorf("synthesis should see step results, got %q", synthesis[1].Content)
+	}
+}
+
+func TestPlanExecute_replansAfterFailedStep(t *testing.T) {
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{
+		{Content: `{"steps":[{"description":"try A"},{"description":"then B"}]}`},
+		{Content: `{"status":"failed","result":"A is unavailable"}`},
+		{Content: `{"steps":[{"description":"try C"}]}`},
+		{Content: `{"status":"done","result":"C worked"}`},
+		{Content: "done via C"},
+	}}
+	engine := NewEngine(adapter, 1, 1)
+
+	res := runSingleTask(t, engine, &Task{ID: "replan", Input: "go", Options: TaskOptions{Strategy: StrategyPlanExecute}})
+	if res.Error != nil || res.Output != "done via C" {
+		t.Fatalf("unexpected result %q (err %v)", res.Output, res.Error)
+	}
+	want := []StepState{StepFailed, StepSkipped, StepDone}
+	if len(res.Steps) != len(want) {
+		t.Fatalf("unexpected steps %+v", res.Steps)
+	}
+	for i, s := range want {
+		if res.Steps[i].State != s {
+			t.Errorf("step %d: state %s, want %s", i, res.Steps[i].State, s)
+		}
+	}
+	if replan := adapter.requests[2][1].Content; !strings.Contains(replan, "A is unavailable") {
+		t.Errorf("re-plan prompt should carry the failure, got %q", replan)
+	}
+}
+
+func TestPlanExecute_recoversFromCheckpoint(t *testing.T) {
+	j := openTestJournal(t, filepath.Join(t.TempDir(), "tasks.journal"))
+	gate := &gateTool{entered: make(chan struct{}), release: make(chan struct{})}
+	first := NewEngine(&scriptedLLM{responses: []llm.LLMResponse{
+		{Content: `{"steps":[{"description":"count things"},{"description":"wait"}]}`},
+		{ToolCalls: []llm.ToolCall{{ID: "c1", Name: "count"}}},
+		{Content: `{"status":"done","result":"3 things"}`},
+		{ToolCalls: []llm.ToolCall{{ID: "c2", Name: "gate"}}},
+	}}, 1, 1).WithJournal(j)
+	registerAll(t, first, &countingTool{name: "count"}, gate)
+	first.Start()
+	_ = first.Submit(&Task{ID: "plan", Input: "how many?", Options: TaskOptions{Strategy: StrategyPlanExecute}})
+	<-gate.entered
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, _ = first.Shutdown(ctx) // crash mid-way through the second step
+
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{
+		{Content: `{"status":"done","result":"waited"}`},
+		{Content: "there are three things"},
+	}}
+	tool := &countingTool{name: "count"}
+	next := NewEngine(adapter, 1, 1).WithJournal(j)
+	registerAll(t, next, tool, &gateTool{entered: make(chan struct{}), release: make(chan struct{})})
+	if n, err := next.Recover(); err != nil || n != 1 {
+		t.Fatalf("Recover: n=%d err=%v", n, err)
+	}
+	next.Start()
+	defer next.Stop()
+	res := <-next.Results()
+
+	if res.Error != nil || res.Output != "there are three things" {
+		t.Fatalf("unexpected result %q (err %v)", res.Output, res.Error)
+	}
+	if adapter.callCount() != 2 || tool.count() != 0 {
+		t.Errorf("recovery should neither re-plan nor redo step 1: %d LLM calls, %d tool runs", adapter.callCount(), tool.count())
+	}
+	if !strings.Contains(adapter.requests[0][1].Content, "3 things") {
+		t.Errorf("resumed step should see earlier results, got %q", adapter.requests[0][1].Content)
+	}
+	if len(res.Steps) != 2 || res.Steps[0].Result != "3 things" || res.Steps[1].State != StepDone {
+		t.Errorf("unexpected steps %+v", res.Steps)
+	}
+}
+
+func TestPlanExecute_givesUpAfterReplanLimit(t *testing.T) {
+	adapter := &scriptedLLM{}
+	for range maxReplans + 1 {
+		adapter.responses = append(adapter.responses,
+			llm.LLMResponse{Content: `{"steps":[{"description":"try"}]}`},
+			llm.LLMResponse{Content: `{"status":"failed","result":"nope"}`},
+		)
+	}
+	engine := NewEngine(adapter, 1, 1)
+
+	res := runSingleTask(t, engine, &Task{ID: "stuck", Input: "go", Options: TaskOptions{Strategy: StrategyPlanExecute}})
+	if !errors.Is(res.Error, ErrPlanFailed) {
+		t.Fatalf("want ErrPlanFailed, got %v", res.Error)
+	}
+	if adapter.callCount() != 2*(maxReplans+1) {
+		t.Errorf("want %d LLM calls, got %d", 2*(maxReplans+1), adapter.callCount())
+	}
+}
+
+type echoStrategy struct{}
+
+func (echoStrategy) Name() string { return "echo" }
+
+func (echoStrategy) Run(ctx context.Context, run *AgentRun) (string, error) {
+	resp, err := run.Generate(ctx, 0, []llm.Message{{Role: "user", Content: run.Task.Input}}, nil)
+	if err != nil {
+		return "", err
+	}
+	run.SetSteps([]StepStatus{{Description: "echo", State: StepDone}})
+	return "echo: " + resp.Content, nil
+}
+
+func TestStrategy_customAndUnknown(t *testing.T) {
+	engine := NewEngine(&scriptedLLM{responses: []llm.LLMResponse{{Content: "hi", TokenUsage: llm.TokenUsage{TotalTokens: 4}}}}, 1, 1)
+	engine.RegisterStrategy(echoStrategy{})
+
+	res := runSingleTask(t, engine, &Task{ID: "custom", Input: "x", Options: TaskOptions{Strategy: "echo"}})
+	if res.Error != nil || res.Output != "echo: hi" || len(res.Steps) != 1 || res.Usage.TotalTokens != 4 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+
+	engine = NewEngine(&scriptedLLM{responses: []llm.LLMResponse{{Content: "hi"}}}, 1, 1)
+	res = runSingleTask(t, engine, &Task{ID: "unknown", Input: "x", Options: TaskOptions{Strategy: "tree_of_thought"}})
+	if !errors.Is(res.Error, ErrUnknownStrategy) {
+		t.Fatalf("want ErrUnknownStrategy, got %v", res.Error)
+	}
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fzihak/aethercore/core/llm"
+	"github.com/fzihak/aethercore/core/schema"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+var (
+	ErrInvalidOutputSchema     = errors.New("invalid task output schema")
+	ErrInvalidStructuredOutput = errors.New("final answer does not match the output schema")
+)
+
+// defaultOutputRetries is how many times the model is re-prompted after a
+// final answer fails OutputSchema validation.
+const defaultOutputRetries = 2
+
+// structuredOutput enforces TaskOptions.OutputSchema on a task's final answer.
+// A nil *structuredOutput accepts any answer.
+type structuredOutput struct {
+	raw      json.RawMessage
+	schema   *schema.Schema
+	retries  int
+	attempts int // corrective re-prompts issued so far
+}
+
+// newStructuredOutput compiles the task's output schema, if any.
+func newStructuredOutput(o *TaskOptions) (*structuredOutput, error) {
+	if raw := bytes.TrimSpace(o.OutputSchema); len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	s, err := schema.Compile(o.OutputSchema)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidOutputSchema, err)
+	}
+	retries := o.OutputRetries
+	if retries == 0 {
+		retries = defaultOutputRetries
+	}
+	return &structuredOutput{raw: o.OutputSchema, schema: s, retries: max(retries, 0)}, nil
+}
+
+// bind asks the adapter for schema-constrained content on turns where that
+// cannot suppress tool calling: turns offering no tools, and corrective turns.
+func (so *structuredOutput) bind(ctx context.Context, toolsOffered bool) context.Context {
+	if so == nil || (toolsOffered && so.attempts == 0) {
+		return ctx
+	}
+	return llm.WithResponseFormat(ctx, so.raw)
+}
+
+// accept validates a final answer. It returns the parsed document on success;
+// a corrective user message when the model should try again; or an error
+// wrapping ErrInvalidStructuredOutput once the retries are exhausted.
+func (so *structuredOutput) accept(content string) (json.RawMessage, *llm.Message, error) {
+	if so == nil {
+		return nil, nil, nil
+	}
+	doc := extractJSON(content)
+	err := so.schema.Validate([]byte(doc))
+	if err == nil {
+		var compact bytes.Buffer
+		if cerr := json.Compact(&compact, []byte(doc)); cerr != nil {
+			return json.RawMessage(doc), nil, nil
+		}
+		return json.RawMessage(compact.Bytes()), nil, nil
+	}
+
+	violations := []schema.Violation{{Path: "$", Message: "answer is not valid JSON"}}
+	var ve *schema.ValidationError
+	if errors.As(err, &ve) {
+		violations = ve.Violations
+	}
+	if so.attempts >= so.retries {
+		return nil, nil, fmt.Errorf("%w after %d retries: %w", ErrInvalidStructuredOutput, so.attempts, err)
+	}
+	so.attempts++
+
+	feedback, _ := json.Marshal(struct {
+		Error      string             `json:"error"`
+		Violations []schema.Violation `json:"violations"`
+		Schema     json.RawMessage    `json:"schema"`
+		Hint       string             `json:"hint"`
+	}{"invalid_final_answer", violations, so.raw, "Reply again with only a JSON document that conforms to the schema."})
+	return nil, &llm.Message{Role: "user", Content: string(feedback)}, nil
+}
+
+// extractJSON strips surrounding whitespace and a Markdown code fence, which
+// models commonly wrap around JSON answers.
+func extractJSON(content string) string {
+	s := strings.TrimSpace(content)
+	if !strings.HasPrefix(s, "```") {
+		return s
+	}
+	if nl := strings.IndexByte(s, '\n'); nl >= 0 {
+		s = s[nl+1:]
+	} else {
+		s = strings.TrimPrefix(s, "```")
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "```"))
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+const verdictSchema = `{
+	"type": "object",
+	"properties": {"verdict": {"enum": ["pass", "fail"]}, "score": {"type": "integer"}},
+	"required": ["verdict", "score"]
+}`
+
+func TestStructuredOutput_parsesFencedAnswer(t *testing.T) {
+	raw := "```json\n{ \"verdict\": \"pass\", \"score\": 9 }\n```"
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{{Content: raw}}}
+	engine := NewEngine(adapter, 1, 1)
+
+	res := runSingleTask(t, engine, &Task{ID: "so", Input: "grade", Options: TaskOptions{OutputSchema: json.RawMessage(verdictSchema)}})
+	if res.Error != nil {
+		t.Fatalf("unexpected error: %v", res.Error)
+	}
+	if res.Output != raw || string(res.JSON) != `{"verdict":"pass","score":9}` {
+		t.Errorf("want raw output and compact JSON, got %q / %s", res.Output, res.JSON)
+	}
+	if string(adapter.formats[0]) != verdictSchema {
+		t.Errorf("a turn without tools should request the schema format, got %s", adapter.formats[0])
+	}
+}
+
+func TestStructuredOutput_repromptsWithViolations(t *testing.T) {
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{
+		{ToolCalls: []llm.ToolCall{{ID: "c1", Name: "count"}}},
+		{Content: `{"verdict":"maybe"}`},
+		{Content: `{"verdict":"fail","score":2}`},
+	}}
+	engine := NewEngine(adapter, 1, 1)
+	registerAll(t, engine, &countingTool{name: "count"})
+
+	res := runSingleTask(t, engine, &Task{ID: "retry", Input: "grade", Options: TaskOptions{OutputSchema: json.RawMessage(verdictSchema)}})
+	if res.Error != nil || string(res.JSON) != `{"verdict":"fail","score":2}` {
+		t.Fatalf("want corrected answer, got %s (err %v)", res.JSON, res.Error)
+	}
+	if adapter.callCount() != 3 {
+		t.Fatalf("want 3 LLM calls, got %d", adapter.callCount())
+	}
+	if adapter.formats[0] != nil || adapter.formats[2] == nil {
+		t.Errorf("format should be requested only on the corrective turn when tools are offered: %q", adapter.formats)
+	}
+	feedback := adapter.requests[2][len(adapter.requests[2])-1]
+	if feedback.Role != "user" || !strings.Contains(feedback.Content, "invalid_final_answer") || !strings.Contains(feedback.Content, "$.score") {
+		t.Errorf("unexpected corrective message %+v", feedback)
+	}
+}
+
+func TestStructuredOutput_retriesExhausted(t *testing.T) {
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{{Content: "not json"}}}
+	engine := NewEngine(adapter, 1, 1)
+
+	res := runSingleTask(t, engine, &Task{ID: "exhausted", Input: "grade", Options: TaskOptions{
+		OutputSchema: json.RawMessage(verdictSchema), OutputRetries: 1,
+	}})
+	if !errors.Is(res.Error, ErrInvalidStructuredOutput) {
+		t.Fatalf("want ErrInvalidStructuredOutput, got %v", res.Error)
+	}
+	if adapter.callCount() != 2 {
+		t.Errorf("want original attempt plus one retry, got %d calls", adapter.callCount())
+	}
+
+	bad := runSingleTask(t, NewEngine(adapter, 1, 1), &Task{ID: "bad", Input: "grade", Options: TaskOptions{
+		OutputSchema: json.RawMessage(`{"type":"nope"}`),
+	}})
+	if !errors.Is(bad.Error, ErrInvalidOutputSchema) {
+		t.Errorf("want ErrInvalidOutputSchema, got %v", bad.Error)
+	}
+}
@@ -0,0 +1,231 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+var (
+	ErrTaskNotFound  = errors.New("task not found")
+	ErrTaskCancelled = errors.New("task cancelled")
+)
+
+// TaskState is the coarse lifecycle phase of a submitted task.
+type TaskState string
+
+const (
+	TaskQueued      TaskState = "queued"
+	TaskRunning     TaskState = "running"
+	TaskWaitingTool TaskState = "waiting_tool"
+	TaskRetrying    TaskState = "retrying" // backing off before another attempt
+	TaskDone        TaskState = "done"
+	TaskFailed      TaskState = "failed"
+	TaskCancelled   TaskState = "cancelled"
+)
+
+// Terminal reports whether the state is final.
+func (s TaskState) Terminal() bool {
+	return s == TaskDone || s == TaskFailed || s == TaskCancelled
+}
+
+// taskStatusRetention bounds how many finished tasks remain queryable via Status.
+const taskStatusRetention = 1024
+
+// TaskStatus is a point-in-time snapshot of what a task is doing.
+type TaskStatus struct {
+	TaskID    string
+	State     TaskState
+	Iteration int    // current ReAct iteration (0-based) while running
+	Tool      string // tool being executed while State == TaskWaitingTool
+	UpdatedAt time.Time
+}
+
+// taskHandle is the engine-side control block for one submitted task.
+type taskHandle struct {
+	mu     sync.Mutex
+	status TaskStatus
+	cancel context.CancelCauseFunc
+}
+
+func (h *taskHandle) set(state TaskState, iteration int, tool string) {
+	h.mu.Lock()
+	h.status.State = state
+	h.status.Iteration = iteration
+	h.status.Tool = tool
+	h.status.UpdatedAt = time.Now()
+	h.mu.Unlock()
+}
+
+func (h *taskHandle) snapshot() TaskStatus {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.status
+}
+
+// taskTracker indexes task handles by ID for Cancel and Status lookups.
+// Finished tasks are retained in FIFO order up to taskStatusRetention.
+type taskTracker struct {
+	mu      sync.Mutex
+	handles map[string]*taskHandle
+	retired []string
+}
+
+func newTaskTracker() *taskTracker {
+	return &taskTracker{handles: make(map[string]*taskHandle)}
+}
+
+func (tr *taskTracker) add(id string, h *taskHandle) {
+	tr.mu.Lock()
+	tr.handles[id] = h
+	tr.mu.Unlock()
+}
+
+func (tr *taskTracker) get(id string) (*taskHandle, bool) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	h, ok := tr.handles[id]
+	return h, ok
+}
+
+// remove drops id only if it still maps to h, leaving newer submissions intact.
+func (tr *taskTracker) remove(id string, h *taskHandle) {
+	tr.mu.Lock()
+	if tr.handles[id] == h {
+		delete(tr.handles, id)
+	}
+	tr.mu.Unlock()
+}
+
+// retire records that id reached a terminal state and evicts the oldest
+// finished entries beyond the retention window.
+func (tr *taskTracker) retire(id string) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.retired = append(tr.retired, id)
+	for len(tr.retired) > taskStatusRetention {
+		oldest := tr.retired[0]
+		tr.retired = tr.retired[1:]
+		// A later submission may have reused the ID; only evict finished handles.
+		if h, ok := tr.handles[oldest]; ok && h.snapshot().State.Terminal() {
+			delete(tr.handles, oldest)
+		}
+	}
+}
+
+// SubmitContext enqueues a task bound to ctx. Cancelling ctx, or reaching its
+// deadline, aborts the task's in-flight LLM call and tool execution.
+// Task IDs should be unique among in-flight tasks for Cancel and Status to be meaningful.
+func (e *Engine) SubmitContext(ctx context.Context, t *Task) error {
+	return e.enqueue(ctx, t, true, false)
+}
+
+// enqueue registers and queues a task. When journal is set and the engine has
+// a journal attached, the submission is durably recorded before the task
+// becomes visible to workers. When wait is set, a full queue or tenant quota
+// blocks until space frees up or ctx ends.
+func (e *Engine) enqueue(ctx context.Context, t *Task, journal, wait bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if journal && e.journal != nil {
+		entry := &JournalEntry{Type: JournalSubmitted, TaskID: t.ID, Timestamp: time.Now(), Task: &JournaledTask{
+			ID: t.ID, System: t.System, Input: t.Input, CreatedAt: t.CreatedAt, Options: t.Options,
+		}}
+		if err := e.journal.Append(entry); err != nil {
+			return fmt.Errorf("journal_submit_failed: %w", err)
+		}
+	}
+
+	taskCtx, cancel := context.WithCancelCause(ctx)
+	h := &taskHandle{
+		status: TaskStatus{TaskID: t.ID, State: TaskQueued, UpdatedAt: time.Now()},
+		cancel: cancel,
+	}
+	t.ctx = taskCtx
+	t.handle = h
+
+	// Register before enqueueing so a fast worker can never retire the task
+	// ahead of its registration.
+	e.tracker.add(t.ID, h)
+	push := e.queue.push
+	if wait {
+		push = func(t *Task) error { return e.queue.pushWait(ctx, t) }
+	}
+	if err := push(t); err != nil {
+		e.tracker.remove(t.ID, h)
+		cancel(nil)
+		t.ctx = nil
+		t.handle = nil
+		// Only close out a submission this call journaled: a recovered task
+		// that cannot be queued must stay pending for the next Recover.
+		if journal {
+			e.journalAppend(&JournalEntry{Type: JournalCompleted, TaskID: t.ID, Error: err.Error()})
+		}
+		return err
+	}
+	return nil
+}
+
+// Cancel aborts a queued or running task. A queued task is skipped when a
+// worker picks it up; a running task has its context cancelled. Either way the
+// task's Result carries an error wrapping ErrTaskCancelled.
+func (e *Engine) Cancel(taskID string) error {
+	h, ok := e.tracker.get(taskID)
+	if !ok || h.snapshot().State.Terminal() {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
+	}
+	h.cancel(nil)
+	return nil
+}
+
+// Status reports what a task is currently doing. Finished tasks remain
+// queryable for a bounded window; unknown IDs return ErrTaskNotFound.
+func (e *Engine) Status(taskID string) (TaskStatus, error) {
+	h, ok := e.tracker.get(taskID)
+	if !ok {
+		return TaskStatus{}, fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
+	}
+	return h.snapshot(), nil
+}
+
+// context returns the task's submission context, or Background for tasks that
+// never passed through SubmitContext.
+func (t *Task) context() context.Context {
+	if t.ctx == nil {
+		return context.Background()
+	}
+	return t.ctx
+}
+
+// setStatus updates the task's control block, if it has one.
+func (t *Task) setStatus(state TaskState, iteration int, tool string) {
+	if t.handle != nil {
+		t.handle.set(state, iteration, tool)
+	}
+}
+
+// finish records the terminal state derived from the execution error, releases
+// the task context and retires the task from the active set.
+func (e *Engine) finish(t *Task, err error) error {
+	state := TaskDone
+	if err != nil {
+		state = TaskFailed
+		if t.ctx != nil && errors.Is(t.ctx.Err(), context.Canceled) && errors.Is(err, context.Canceled) {
+			state = TaskCancelled
+			err = fmt.Errorf("%w: %w", ErrTaskCancelled, err)
+		}
+	}
+	if t.handle != nil {
+		h := t.handle
+		h.set(state, h.snapshot().Iteration, "")
+		h.cancel(nil)
+		e.tracker.retire(t.ID)
+	}
+	return err
+}
@@ -0,0 +1,202 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+// blockingLLM blocks every GenerateWithTools call until its context ends.
+type blockingLLM struct {
+	calls   chan struct{}
+	release chan struct{}
+}
+
+func newBlockingLLM() *blockingLLM {
+	return &blockingLLM{calls: make(chan struct{}, 16), release: make(chan struct{})}
+}
+
+func (b *blockingLLM) Generate(_ context.Context, _, _ string) (string, error) { return "", nil }
+func (b *blockingLLM) Name() string                                            { return "blocking" }
+func (b *blockingLLM) GenerateWithTools(ctx context.Context, _ []llm.Message, _ []llm.ToolManifest) (llm.LLMResponse, error) {
+	b.calls <- struct{}{}
+	select {
+	case <-ctx.Done():
+		return llm.LLMResponse{}, ctx.Err()
+	case <-b.release:
+		return llm.LLMResponse{Content: "released"}, nil
+	}
+}
+
+// gateTool blocks until released so tests can observe the waiting_tool state.
+type gateTool struct {
+	once    sync.Once
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (g *gateTool) Manifest() llm.ToolManifest { return llm.ToolManifest{Name: "gate"} }
+func (g *gateTool) Execute(ctx context.Context, _ string) (string, error) {
+	g.once.Do(func() { close(g.entered) })
+	select {
+	case <-g.release:
+		return "opened", nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// toolThenTextLLM requests the named tool once, then answers with text.
+type toolThenTextLLM struct {
+	tool  string
+	calls int
+}
+
+func (m *toolThenTextLLM) Generate(_ context.Context, _, _ string) (string, error) { return "", nil }
+func (m *toolThenTextLLM) Name() string                                            { return "tool_then_text" }
+func (m *toolThenTextLLM) GenerateWithTools(_ context.Context, _ []llm.Message, _ []llm.ToolManifest) (llm.LLMResponse, error) {
+	m.calls++
+	if m.calls == 1 {
+		return llm.LLMResponse{ToolCalls: []llm.ToolCall{{ID: "c1", Name: m.tool, Arguments: "{}"}}}, nil
+	}
+	return llm.LLMResponse{Content: "finished"}, nil
+}
+
+// waitForState polls Status until the task reaches want or the test times out.
+func waitForState(t *testing.T, e *Engine, taskID string, want TaskState) TaskStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		st, err := e.Status(taskID)
+		if err == nil && st.State == want {
+			return st
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("task %s never reached state %s", taskID, want)
+	return TaskStatus{}
+}
+
+func TestEngine_CancelRunningTask(t *testing.T) {
+	adapter := newBlockingLLM()
+	engine := NewEngine(adapter, 1, 4)
+	engine.Start()
+	defer engine.Stop()
+
+	if err := engine.Submit(&Task{ID: "c1", Input: "wait"}); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	<-adapter.calls
+	if err := engine.Cancel("c1"); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+
+	res := <-engine.Results()
+	if !errors.Is(res.Error, ErrTaskCancelled) {
+		t.Fatalf("want ErrTaskCancelled, got %v", res.Error)
+	}
+	if st, _ := engine.Status("c1"); st.State != TaskCancelled {
+		t.Errorf("want state=%s, got %s", TaskCancelled, st.State)
+	}
+	if err := engine.Cancel("c1"); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("cancelling a finished task: want ErrTaskNotFound, got %v", err)
+	}
+}
+
+func TestEngine_CancelQueuedTask(t *testing.T) {
+	adapter := newBlockingLLM()
+	engine := NewEngine(adapter, 1, 4)
+
+	if err := engine.Submit(&Task{ID: "q1", Input: "never runs"}); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if st, err := engine.Status("q1"); err != nil || st.State != TaskQueued {
+		t.Fatalf("want queued status, got %+v (err=%v)", st, err)
+	}
+	if err := engine.Cancel("q1"); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+
+	engine.Start()
+	defer engine.Stop()
+
+	res := <-engine.Results()
+	if !errors.Is(res.Error, ErrTaskCancelled) {
+		t.Fatalf("want ErrTaskCancelled, got %v", res.Error)
+	}
+	if len(adapter.calls) != 0 {
+		t.Error("cancelled queued task must not reach the LLM")
+	}
+}
+
+func TestEngine_SubmitContextDeadline(t *testing.T) {
+	adapter := newBlockingLLM()
+	engine := NewEngine(adapter, 1, 4)
+	engine.Start()
+	defer engine.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := engine.SubmitContext(ctx, &Task{ID: "d1", Input: "slow"}); err != nil {
+		t.Fatalf("SubmitContext: %v", err)
+	}
+
+	res := <-engine.Results()
+	if !errors.Is(res.Error, context.DeadlineExceeded) {
+		t.Fatalf("want DeadlineExceeded, got %v", res.Error)
+	}
+	if st, _ := engine.Status("d1"); st.State != TaskFailed {
+		t.Errorf("want state=%s, got %s", TaskFailed, st.State)
+	}
+}
+
+func TestEngine_SubmitContextAlreadyDone(t *testing.T) {
+	engine := NewEngine(&MockLLMAdapter{}, 1, 4)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := engine.SubmitContext(ctx, &Task{ID: "x"}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("want context.Canceled, got %v", err)
+	}
+	if _, err := engine.Status("x"); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("rejected task must not be tracked, got %v", err)
+	}
+}
+
+func TestEngine_StatusWaitingOnTool(t *testing.T) {
+	gate := &gateTool{entered: make(chan struct{}), release: make(chan struct{})}
+	engine := NewEngine(&toolThenTextLLM{tool: "gate"}, 1, 4)
+	if err := engine.RegisterTool(gate); err != nil {
+		t.Fatalf("RegisterTool: %v", err)
+	}
+	engine.Start()
+	defer engine.Stop()
+
+	if err := engine.Submit(&Task{ID: "w1", Input: "open the gate"}); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	<-gate.entered
+	st := waitForState(t, engine, "w1", TaskWaitingTool)
+	if st.Tool != "gate" || st.Iteration != 0 {
+		t.Errorf("unexpected status while waiting: %+v", st)
+	}
+	close(gate.release)
+
+	res := <-engine.Results()
+	if res.Error != nil {
+		t.Fatalf("unexpected error: %v", res.Error)
+	}
+	if st, _ := engine.Status("w1"); st.State != TaskDone {
+		t.Errorf("want state=%s, got %s", TaskDone, st.State)
+	}
+}
+
+func TestEngine_StatusUnknownTask(t *testing.T) {
+	engine := NewEngine(&MockLLMAdapter{}, 1, 1)
+	if _, err := engine.Status("nope"); !errors.Is(err, ErrTaskNotFound) {
+		t.Fatalf("want ErrTaskNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+const (
+	// defaultSystemPrompt is used when a Task carries no System prompt.
+	defaultSystemPrompt = "You are AetherCore Kernel. Execute the objective using tools."
+	// defaultTaskTimeout bounds a task that sets neither Timeout nor Deadline.
+	defaultTaskTimeout = 5 * time.Minute
+)
+
+// ErrTaskTimeout is returned when a task runs past its Timeout or Deadline.
+// Such tasks are not retried: attempts share a single overall deadline.
+var ErrTaskTimeout = errors.New("task exceeded its timeout or deadline")
+
+// TaskOptions carries optional per-task execution settings.
+// The zero value reproduces the engine defaults.
+type TaskOptions struct {
+	// MaxIterations caps ReAct iterations. Zero means maxAgentIterations.
+	MaxIterations int
+	// Timeout bounds total execution time. Zero means defaultTaskTimeout.
+	Timeout time.Duration
+	// Deadline is an absolute cutoff; when set, the earlier of Deadline and
+	// Timeout applies.
+	Deadline time.Time
+	// Model is a model/provider hint forwarded to the adapter via llm.WithModelHint.
+	Model string
+	// Tools restricts which registered tools the model may see and call.
+	// Nil exposes every registered tool; an empty non-nil slice exposes none.
+	Tools []string
+	// MaxParallelTools bounds concurrent tool calls within one model turn.
+	// Zero means defaultToolParallelism; 1 forces sequential execution.
+	MaxParallelTools int
+	// TokenBudget caps cumulative prompt/completion/total tokens for the task.
+	TokenBudget TokenBudget
+	// OutputSchema, when set, requires the final answer to be JSON conforming
+	// to this JSON Schema. The parsed document is returned in Result.JSON.
+	OutputSchema json.RawMessage `json:",omitempty"`
+	// OutputRetries caps re-prompts after a final answer fails OutputSchema
+	// validation. Zero means defaultOutputRetries; negative disables re-prompting.
+	OutputRetries int
+	// Strategy names the AgentStrategy to run. Empty means StrategyReAct.
+	Strategy string
+	// Priority orders the task in the engine queue. Zero is PriorityNormal.
+	Priority Priority
+	// Tenant is the fair-queuing and quota key (a user, chat or module);
+	// see WithScheduling.
+	Tenant string
+	// Retry re-runs the task after transient failures. The zero value disables retries.
+	Retry RetryPolicy
+}
+
+// maxIterations resolves the effective iteration cap.
+func (o *TaskOptions) maxIterations() int {
+	if o.MaxIterations > 0 {
+		return o.MaxIterations
+	}
+	return maxAgentIterations
+}
+
+// maxParallelTools resolves the effective per-turn tool concurrency.
+func (o *TaskOptions) maxParallelTools() int {
+	if o.MaxParallelTools > 0 {
+		return o.MaxParallelTools
+	}
+	return defaultToolParallelism
+}
+
+// bind derives the task's execution context from parent, applying the timeout,
+// deadline and model hint. Expiry sets ErrTaskTimeout as the context's cause.
+func (o *TaskOptions) bind(parent context.Context) (context.Context, context.CancelFunc) {
+	timeout := o.Timeout
+	if timeout <= 0 {
+		timeout = defaultTaskTimeout
+	}
+	ctx, cancelTimeout := context.WithTimeoutCause(parent, timeout, ErrTaskTimeout)
+	cancel := cancelTimeout
+	if !o.Deadline.IsZero() {
+		var cancelDeadline context.CancelFunc
+		ctx, cancelDeadline = context.WithDeadlineCause(ctx, o.Deadline, ErrTaskTimeout)
+		cancel = func() {
+			cancelDeadline()
+			cancelTimeout()
+		}
+	}
+	if o.Model != "" {
+		ctx = llm.WithModelHint(ctx, o.Model)
+	}
+	return ctx, cancel
+}
+
+// toolAllowed reports whether the model may call the named tool.
+func (o *TaskOptions) toolAllowed(name string) bool {
+	if o.Tools == nil {
+		return true
+	}
+	for _, allowed := range o.Tools {
+		if allowed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// filterManifests returns the subset of manifests permitted for this task.
+func (o *TaskOptions) filterManifests(all []llm.ToolManifest) []llm.ToolManifest {
+	if o.Tools == nil {
+		return all
+	}
+	out := make([]llm.ToolManifest, 0, len(o.Tools))
+	for i := range all {
+		if o.toolAllowed(all[i].Name) {
+			out = append(out, all[i])
+		}
+	}
+	return out
+}
+
+// systemPrompt returns the task's system prompt, falling back to the kernel default.
+func (t *Task) systemPrompt() string {
+	if t.System != "" {
+		return t.System
+	}
+	return defaultSystemPrompt
+}
@@ -0,0 +1,181 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+// scriptedLLM replays a fixed list of responses (repeating the last one) and
+// records every request it receives.
+type scriptedLLM struct {
+	mu        sync.Mutex
+	responses []llm.LLMResponse
+	requests  [][]llm.Message
+	tools     [][]llm.ToolManifest
+	hints     []string
+	formats   []json.RawMessage
+}
+
+func (s *scriptedLLM) Generate(_ context.Context, _, _ string) (string, error) { return "", nil }
+func (s *scriptedLLM) Name() string                                            { return "scripted" }
+func (s *scriptedLLM) GenerateWithTools(ctx context.Context, messages []llm.Message, tools []llm.ToolManifest) (llm.LLMResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := len(s.requests)
+	s.requests = append(s.requests, append([]llm.Message(nil), messages...))
+	s.tools = append(s.tools, tools)
+	s.hints = append(s.hints, llm.ModelHintFromContext(ctx))
+	s.formats = append(s.formats, llm.ResponseFormatFromContext(ctx))
+	if i >= len(s.responses) {
+		i = len(s.responses) - 1
+	}
+	return s.responses[i], nil
+}
+
+func (s *scriptedLLM) callCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+// countingTool records how often it was executed.
+type countingTool struct {
+	name  string
+	mu    sync.Mutex
+	calls int
+}
+
+func (c *countingTool) Manifest() llm.ToolManifest { return llm.ToolManifest{Name: c.name} }
+func (c *countingTool) Execute(_ context.Context, _ string) (string, error) {
+	c.mu.Lock()
+	c.calls++
+	c.mu.Unlock()
+	return c.name + " ran", nil
+}
+
+func (c *countingTool) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls
+}
+
+func runSingleTask(t *testing.T, engine *Engine, task *Task) *Result {
+	t.Helper()
+	engine.Start()
+	defer engine.Stop()
+	if err := engine.Submit(task); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	return <-engine.Results()
+}
+
+func TestTaskOptions_systemPromptAndModelHint(t *testing.T) {
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{{Content: "ok"}}}
+	engine := NewEngine(adapter, 1, 1)
+
+	res := runSingleTask(t, engine, &Task{
+		ID:      "sys",
+		System:  "You are a pirate.",
+		Input:   "hello",
+		Options: TaskOptions{Model: "ollama/qwen2.5"},
+	})
+	if res.Error != nil {
+		t.Fatalf("unexpected error: %v", res.Error)
+	}
+	if got := adapter.requests[0][0]; got.Role != "system" || got.Content != "You are a pirate." {
+		t.Errorf("task system prompt not honoured: %+v", got)
+	}
+	if adapter.hints[0] != "ollama/qwen2.5" {
+		t.Errorf("want model hint forwarded, got %q", adapter.hints[0])
+	}
+}
+
+func TestTaskOptions_defaultSystemPrompt(t *testing.T) {
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{{Content: "ok"}}}
+	res := runSingleTask(t, NewEngine(adapter, 1, 1), &Task{ID: "def", Input: "hello"})
+	if res.Error != nil {
+		t.Fatalf("unexpected error: %v", res.Error)
+	}
+	if adapter.requests[0][0].Content != defaultSystemPrompt {
+		t.Errorf("want default system prompt, got %q", adapter.requests[0][0].Content)
+	}
+}
+
+func TestTaskOptions_maxIterations(t *testing.T) {
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{{
+		ToolCalls: []llm.ToolCall{{ID: "c", Name: "echo", Arguments: "{}"}},
+	}}}
+	engine := NewEngine(adapter, 1, 1)
+	if err := engine.RegisterTool(&testEchoTool{name: "echo"}); err != nil {
+		t.Fatalf("RegisterTool: %v", err)
+	}
+
+	res := runSingleTask(t, engine, &Task{ID: "it", Input: "loop", Options: TaskOptions{MaxIterations: 2}})
+	if !errors.Is(res.Error, ErrMaxIterationsExceeded) {
+		t.Fatalf("want ErrMaxIterationsExceeded, got %v", res.Error)
+	}
+	if adapter.callCount() != 2 {
+		t.Errorf("want 2 LLM calls, got %d", adapter.callCount())
+	}
+}
+
+func TestTaskOptions_timeout(t *testing.T) {
+	engine := NewEngine(newBlockingLLM(), 1, 1)
+	res := runSingleTask(t, engine, &Task{ID: "to", Input: "slow", Options: TaskOptions{Timeout: 30 * time.Millisecond}})
+	if !errors.Is(res.Error, context.DeadlineExceeded) {
+		t.Fatalf("want DeadlineExceeded, got %v", res.Error)
+	}
+}
+
+func TestTaskOptions_deadline(t *testing.T) {
+	engine := NewEngine(newBlockingLLM(), 1, 1)
+	res := runSingleTask(t, engine, &Task{ID: "dl", Input: "slow", Options: TaskOptions{Deadline: time.Now().Add(30 * time.Millisecond)}})
+	if !errors.Is(res.Error, context.DeadlineExceeded) {
+		t.Fatalf("want DeadlineExceeded, got %v", res.Error)
+	}
+}
+
+func TestTaskOptions_toolAllowlist(t *testing.T) {
+	allowed := &countingTool{name: "allowed"}
+	hidden := &countingTool{name: "hidden"}
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{
+		{ToolCalls: []llm.ToolCall{
+			{ID: "c1", Name: "hidden", Arguments: "{}"},
+			{ID: "c2", Name: "allowed", Arguments: "{}"},
+		}},
+		{Content: "done"},
+	}}
+	engine := NewEngine(adapter, 1, 1)
+	for _, tool := range []Tool{allowed, hidden} {
+		if err := engine.RegisterTool(tool); err != nil {
+			t.Fatalf("RegisterTool: %v", err)
+		}
+	}
+
+	res := runSingleTask(t, engine, &Task{ID: "al", Input: "use tools", Options: TaskOptions{Tools: []string{"allowed"}}})
+	if res.Error != nil {
+		t.Fatalf("unexpected error: %v", res.Error)
+	}
+	if got := adapter.tools[0]; len(got) != 1 || got[0].Name != "allowed" {
+		t.Errorf("model should only see the allowlisted tool, got %+v", got)
+	}
+	if hidden.count() != 0 {
+		t.Error("tool outside the allowlist must not execute")
+	}
+	if allowed.count() != 1 {
+		t.Errorf("allowlisted tool should execute once, got %d", allowed.count())
+	}
+
+	toolMsg := adapter.requests[1][len(adapter.requests[1])-1]
+	if len(toolMsg.ToolResults) != 2 || !toolMsg.ToolResults[0].IsError ||
+		!strings.Contains(toolMsg.ToolResults[0].Content, ErrToolNotPermitted.Error()) {
+		t.Errorf("expected a not-permitted error result for the hidden tool, got %+v", toolMsg.ToolResults)
+	}
+}
@@ -0,0 +1,246 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+// ErrTenantQuotaExceeded is returned by Submit when a tenant already has its
+// maximum number of tasks queued or running.
+var ErrTenantQuotaExceeded = errors.New("tenant task quota exceeded")
+
+// Priority orders queued tasks. Higher priorities are always dequeued first;
+// tenants share capacity fairly within a priority level.
+type Priority int
+
+const (
+	PriorityLow    Priority = -1 // background and scheduled jobs
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1 // interactive requests
+)
+
+// SchedulingConfig configures weighted fair queuing across tenants, keyed by
+// TaskOptions.Tenant (a user, chat or module). Tasks without a tenant share
+// the "" tenant.
+type SchedulingConfig struct {
+	// Weights sets each tenant's relative share of dequeues under contention.
+	// Unlisted tenants get DefaultWeight.
+	Weights map[string]int
+	// DefaultWeight applies to tenants absent from Weights. Zero means 1.
+	DefaultWeight int
+	// MaxTenantTasks caps the tasks a tenant may have queued or running at
+	// once. Zero means unlimited.
+	MaxTenantTasks int
+	// TenantQuotas overrides MaxTenantTasks for individual tenants.
+	TenantQuotas map[string]int
+}
+
+func (c *SchedulingConfig) weight(tenant string) float64 {
+	if w := c.Weights[tenant]; w > 0 {
+		return float64(w)
+	}
+	if c.DefaultWeight > 0 {
+		return float64(c.DefaultWeight)
+	}
+	return 1
+}
+
+func (c *SchedulingConfig) quota(tenant string) int {
+	if q, ok := c.TenantQuotas[tenant]; ok {
+		return q
+	}
+	return c.MaxTenantTasks
+}
+
+// WithScheduling enables per-tenant weights and task quotas. Call before Start.
+func (e *Engine) WithScheduling(cfg SchedulingConfig) *Engine {
+	e.queue.mu.Lock()
+	defer e.queue.mu.Unlock()
+	e.queue.cfg = cfg
+	return e
+}
+
+// taskQueue is the engine's bounded run queue. It dequeues by priority and,
+// within a priority, by start-time fair queuing across tenants: each dequeue
+// advances the tenant's virtual time by 1/weight, and the backlogged tenant
+// with the lowest virtual time goes next. A tenant that was idle resumes at
+// the current virtual time, so idleness earns no burst credit.
+type taskQueue struct {
+	mu       sync.Mutex
+	cfg      SchedulingConfig
+	capacity int
+	size     int
+	vtime    float64
+	tenants  map[string]*tenantQueue
+	inflight int  // queued plus running, across tenants
+	closed   bool // no further pushes
+
+	// changed is closed and replaced whenever space, quota or the in-flight
+	// count changes, waking pushWait and drain.
+	changed chan struct{}
+	// ready holds one token per queued task; workers receive a token before
+	// calling pop, so pop always finds a task.
+	ready chan struct{}
+}
+
+// tenantQueue is one tenant's pending tasks and scheduling state.
+type tenantQueue struct {
+	pass     float64 // virtual time of the tenant's next dequeue
+	inflight int     // queued plus running
+	pending  map[Priority][]*Task
+	queued   int
+}
+
+func newTaskQueue(capacity int) *taskQueue {
+	return &taskQueue{
+		capacity: capacity,
+		tenants:  make(map[string]*tenantQueue),
+		ready:    make(chan struct{}, capacity),
+		changed:  make(chan struct{}),
+	}
+}
+
+// push queues t, enforcing the tenant quota and then the queue bound.
+func (q *taskQueue) push(t *Task) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.pushLocked(t)
+}
+
+// pushWait is push that blocks while the queue or the tenant quota is full.
+func (q *taskQueue) pushWait(ctx context.Context, t *Task) error {
+	for {
+		q.mu.Lock()
+		err := q.pushLocked(t)
+		changed := q.changed
+		q.mu.Unlock()
+		if !errors.Is(err, ErrQueueFull) && !errors.Is(err, ErrTenantQuotaExceeded) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+func (q *taskQueue) pushLocked(t *Task) error {
+	if q.closed {
+		return ErrEngineStopped
+	}
+	tenant := t.Options.Tenant
+	tq := q.tenants[tenant]
+	if limit := q.cfg.quota(tenant); limit > 0 && tq != nil && tq.inflight >= limit {
+		return fmt.Errorf("%w: tenant %q has %d tasks in flight", ErrTenantQuotaExceeded, tenant, tq.inflight)
+	}
+	if q.size >= q.capacity {
+		return ErrQueueFull
+	}
+	if tq == nil {
+		tq = &tenantQueue{pending: make(map[Priority][]*Task)}
+		q.tenants[tenant] = tq
+	}
+	if tq.queued == 0 {
+		tq.pass = max(tq.pass, q.vtime)
+	}
+	tq.pending[t.Options.Priority] = append(tq.pending[t.Options.Priority], t)
+	tq.queued++
+	tq.inflight++
+	q.inflight++
+	q.size++
+	q.ready <- struct{}{} // never blocks: tokens never exceed capacity
+	return nil
+}
+
+// pop removes the next task. Callers must first receive from ready, unless
+// the workers have exited; pop then returns nil once the queue is empty.
+func (q *taskQueue) pop() *Task {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var (
+		best     *tenantQueue
+		bestName string
+		bestPrio Priority
+	)
+	for name, tq := range q.tenants {
+		for prio, tasks := range tq.pending {
+			if len(tasks) == 0 {
+				continue
+			}
+			if best == nil || prio > bestPrio ||
+				(prio == bestPrio && (tq.pass < best.pass || (tq.pass == best.pass && name < bestName))) {
+				best, bestName, bestPrio = tq, name, prio
+			}
+		}
+	}
+
+	if best == nil {
+		return nil
+	}
+	t := best.pending[bestPrio][0]
+	best.pending[bestPrio][0] = nil
+	best.pending[bestPrio] = best.pending[bestPrio][1:]
+	if len(best.pending[bestPrio]) == 0 {
+		delete(best.pending, bestPrio)
+	}
+	best.queued--
+	q.size--
+	q.vtime = best.pass
+	best.pass += 1 / q.cfg.weight(bestName)
+	q.notify()
+	return t
+}
+
+// done releases a dequeued task's slot in its tenant's quota once its
+// result has been published.
+func (q *taskQueue) done(tenant string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	tq, ok := q.tenants[tenant]
+	if !ok {
+		return
+	}
+	tq.inflight--
+	q.inflight--
+	if tq.inflight <= 0 && tq.queued == 0 {
+		delete(q.tenants, tenant)
+	}
+	q.notify()
+}
+
+// close rejects further pushes and wakes blocked submitters.
+func (q *taskQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.notify()
+}
+
+// drain blocks until no task is queued or running, or ctx ends.
+func (q *taskQueue) drain(ctx context.Context) error {
+	for {
+		q.mu.Lock()
+		idle, changed := q.inflight == 0, q.changed
+		q.mu.Unlock()
+		if idle {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// notify wakes everything waiting on changed. Callers hold q.mu.
+func (q *taskQueue) notify() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
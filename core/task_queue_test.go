@@ -0,0 +1,99 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func queueTask(id, tenant string, prio Priority) *Task {
+	return &Task{ID: id, Options: TaskOptions{Tenant: tenant, Priority: prio}}
+}
+
+// drain pops n tasks and returns their IDs.
+func drain(q *taskQueue, n int) []string {
+	ids := make([]string, 0, n)
+	for range n {
+		<-q.ready
+		ids = append(ids, q.pop().ID)
+	}
+	return ids
+}
+
+func TestTaskQueue_higherPriorityFirst(t *testing.T) {
+	q := newTaskQueue(8)
+	for _, task := range []*Task{
+		queueTask("low", "a", PriorityLow),
+		queueTask("normal", "b", PriorityNormal),
+		queueTask("high", "a", PriorityHigh),
+	} {
+		if err := q.push(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := fmt.Sprint(drain(q, 3))
+	if got != "[high normal low]" {
+		t.Errorf("dequeue order %s", got)
+	}
+}
+
+func TestTaskQueue_noisyTenantDoesNotStarveOthers(t *testing.T) {
+	q := newTaskQueue(16)
+	for i := range 6 {
+		_ = q.push(queueTask(fmt.Sprintf("noisy-%d", i), "noisy", PriorityNormal))
+	}
+	_ = q.push(queueTask("quiet-0", "quiet", PriorityNormal))
+	_ = q.push(queueTask("quiet-1", "quiet", PriorityNormal))
+
+	got := drain(q, 4)
+	want := []string{"noisy-0", "quiet-0", "noisy-1", "quiet-1"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("dequeue order %v, want %v", got, want)
+	}
+}
+
+func TestTaskQueue_weightsSplitCapacity(t *testing.T) {
+	q := newTaskQueue(16)
+	q.cfg = SchedulingConfig{Weights: map[string]int{"gold": 2}}
+	for i := range 6 {
+		_ = q.push(queueTask(fmt.Sprintf("g%d", i), "gold", PriorityNormal))
+		_ = q.push(queueTask(fmt.Sprintf("s%d", i), "silver", PriorityNormal))
+	}
+	gold := 0
+	for _, id := range drain(q, 6) {
+		if id[0] == 'g' {
+			gold++
+		}
+	}
+	if gold != 4 {
+		t.Errorf("weight 2 tenant should get 4 of 6 dequeues, got %d", gold)
+	}
+}
+
+func TestSubmit_tenantQuota(t *testing.T) {
+	engine := NewEngine(&MockLLMAdapter{}, 1, 3).WithScheduling(SchedulingConfig{
+		MaxTenantTasks: 2,
+		TenantQuotas:   map[string]int{"vip": 0},
+	})
+
+	for i := range 2 {
+		if err := engine.Submit(queueTask(fmt.Sprintf("u%d", i), "user-1", PriorityNormal)); err != nil {
+			t.Fatalf("submit %d: %v", i, err)
+		}
+	}
+	if err := engine.Submit(queueTask("u2", "user-1", PriorityNormal)); !errors.Is(err, ErrTenantQuotaExceeded) {
+		t.Fatalf("want ErrTenantQuotaExceeded, got %v", err)
+	}
+	if err := engine.Submit(queueTask("v0", "vip", PriorityNormal)); err != nil {
+		t.Fatalf("unlimited tenant rejected: %v", err)
+	}
+	if err := engine.Submit(queueTask("v1", "vip", PriorityNormal)); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("want ErrQueueFull once the queue is saturated, got %v", err)
+	}
+
+	<-engine.queue.ready
+	engine.queue.done(engine.queue.pop().Options.Tenant)
+	if err := engine.Submit(queueTask("u3", "user-1", PriorityNormal)); err != nil {
+		t.Errorf("finished task should free quota: %v", err)
+	}
+}
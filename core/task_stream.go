@@ -0,0 +1,86 @@
+package core
+
+import (
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+// TaskEventType classifies an incremental progress event emitted by a streamed task.
+type TaskEventType string
+
+const (
+	EventContentDelta TaskEventType = "content_delta" // partial assistant text
+	EventToolCall     TaskEventType = "tool_call"     // the model requested a tool
+	EventToolResult   TaskEventType = "tool_result"   // a tool finished (or failed)
+	EventFinal        TaskEventType = "final"         // the task produced its final answer
+	EventError        TaskEventType = "error"         // the task terminated with an error
+	EventRetry        TaskEventType = "retry"         // the task failed transiently and will be retried
+)
+
+// taskEventBuffer is the per-task stream channel capacity. Once it fills up the
+// worker blocks until the consumer catches up, applying natural backpressure.
+const taskEventBuffer = 64
+
+// TaskEvent is a single progress notification for a streamed task.
+// Only the fields relevant to Type are populated.
+type TaskEvent struct {
+	TaskID     string
+	Type       TaskEventType
+	Iteration  int
+	Content    string                // EventContentDelta, EventFinal
+	ToolCall   llm.ToolCall          // EventToolCall, EventToolResult
+	ToolResult llm.ToolResultMessage // EventToolResult
+	Attempt    int                   // EventRetry: the attempt about to start
+	Err        error                 // EventError, EventRetry
+}
+
+// SubmitStream enqueues a task and returns a channel of its progress events:
+// content deltas, tool calls, tool results and finally exactly one EventFinal or
+// EventError, after which the channel is closed. The task's Result is still
+// delivered on Results() as usual. When a transient failure is retried the
+// stream carries an EventRetry: the next attempt starts over, so consumers
+// should discard the content they accumulated before it.
+func (e *Engine) SubmitStream(t *Task) (<-chan TaskEvent, error) {
+	events := make(chan TaskEvent, taskEventBuffer)
+	t.events = events
+	if err := e.Submit(t); err != nil {
+		t.events = nil
+		return nil, err
+	}
+	return events, nil
+}
+
+// emit delivers ev to the task's stream, if any. It blocks while the stream
+// buffer is full and gives up once done is closed; while there is room the
+// event is delivered even if done is already closed.
+//
+//nolint:gocritic // TaskEvent is passed by value so callers can build it inline
+func (t *Task) emit(done <-chan struct{}, ev TaskEvent) {
+	if t.events == nil {
+		return
+	}
+	ev.TaskID = t.ID
+	select {
+	case t.events <- ev:
+	default:
+		select {
+		case t.events <- ev:
+		case <-done:
+		}
+	}
+}
+
+// closeStream emits the terminal event for a streamed task and closes its channel.
+func (t *Task) closeStream(done <-chan struct{}, out string, err error) {
+	if t.events == nil {
+		return
+	}
+	if err != nil {
+		t.emit(done, TaskEvent{Type: EventError, Err: err})
+	} else {
+		t.emit(done, TaskEvent{Type: EventFinal, Content: out})
+	}
+	close(t.events)
+	t.events = nil
+}
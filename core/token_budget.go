@@ -0,0 +1,69 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+var ErrTokenBudgetExceeded = errors.New("token budget exceeded")
+
+// TokenBudget caps the cumulative token usage of a task across all of its LLM
+// calls, including those of failed attempts that were retried. Zero fields are
+// unlimited.
+type TokenBudget struct {
+	MaxPromptTokens     int
+	MaxCompletionTokens int
+	MaxTotalTokens      int
+}
+
+// TokenBudgetError reports which limit a task exceeded and its usage at that point.
+type TokenBudgetError struct {
+	Limit string // "prompt", "completion" or "total"
+	Max   int
+	Used  llm.TokenUsage
+}
+
+func (e *TokenBudgetError) Error() string {
+	return fmt.Sprintf("%s: %s tokens %d > %d", ErrTokenBudgetExceeded.Error(), e.Limit, e.usedFor(), e.Max)
+}
+
+func (e *TokenBudgetError) Unwrap() error { return ErrTokenBudgetExceeded }
+
+func (e *TokenBudgetError) usedFor() int {
+	switch e.Limit {
+	case "prompt":
+		return e.Used.PromptTokens
+	case "completion":
+		return e.Used.CompletionTokens
+	default:
+		return e.Used.TotalTokens
+	}
+}
+
+// check returns a *TokenBudgetError if used exceeds any configured limit.
+func (b *TokenBudget) check(used llm.TokenUsage) error {
+	switch {
+	case b.MaxPromptTokens > 0 && used.PromptTokens > b.MaxPromptTokens:
+		return &TokenBudgetError{Limit: "prompt", Max: b.MaxPromptTokens, Used: used}
+	case b.MaxCompletionTokens > 0 && used.CompletionTokens > b.MaxCompletionTokens:
+		return &TokenBudgetError{Limit: "completion", Max: b.MaxCompletionTokens, Used: used}
+	case b.MaxTotalTokens > 0 && used.TotalTokens > b.MaxTotalTokens:
+		return &TokenBudgetError{Limit: "total", Max: b.MaxTotalTokens, Used: used}
+	}
+	return nil
+}
+
+// addUsage accumulates one LLM call's usage into the running total. Adapters
+// that omit TotalTokens have it derived from the prompt and completion counts.
+func addUsage(total *llm.TokenUsage, u llm.TokenUsage) {
+	if u.TotalTokens == 0 {
+		u.TotalTokens = u.PromptTokens + u.CompletionTokens
+	}
+	total.PromptTokens += u.PromptTokens
+	total.CompletionTokens += u.CompletionTokens
+	total.TotalTokens += u.TotalTokens
+}
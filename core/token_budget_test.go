@@ -0,0 +1,76 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+func TestTokenBudget_check(t *testing.T) {
+	b := TokenBudget{MaxPromptTokens: 100, MaxTotalTokens: 150}
+	if err := b.check(llm.TokenUsage{PromptTokens: 100, CompletionTokens: 50, TotalTokens: 150}); err != nil {
+		t.Fatalf("usage at the limit should pass: %v", err)
+	}
+
+	err := b.check(llm.TokenUsage{PromptTokens: 101, TotalTokens: 101})
+	var be *TokenBudgetError
+	if !errors.As(err, &be) || be.Limit != "prompt" || !errors.Is(err, ErrTokenBudgetExceeded) {
+		t.Fatalf("want prompt TokenBudgetError, got %v", err)
+	}
+
+	err = b.check(llm.TokenUsage{PromptTokens: 10, CompletionTokens: 141, TotalTokens: 151})
+	if !errors.As(err, &be) || be.Limit != "total" {
+		t.Fatalf("want total TokenBudgetError, got %v", err)
+	}
+
+	var unlimited TokenBudget
+	if err := unlimited.check(llm.TokenUsage{TotalTokens: 1 << 30}); err != nil {
+		t.Errorf("zero budget is unlimited: %v", err)
+	}
+}
+
+func TestAddUsage_derivesTotal(t *testing.T) {
+	var total llm.TokenUsage
+	addUsage(&total, llm.TokenUsage{PromptTokens: 3, CompletionTokens: 4})
+	addUsage(&total, llm.TokenUsage{PromptTokens: 1, CompletionTokens: 1, TotalTokens: 2})
+	if total.TotalTokens != 9 || total.PromptTokens != 4 || total.CompletionTokens != 5 {
+		t.Errorf("unexpected accumulated usage: %+v", total)
+	}
+}
+
+func TestEngine_tokenBudgetExceeded(t *testing.T) {
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{{
+		ToolCalls:  []llm.ToolCall{{ID: "c", Name: "echo", Arguments: "{}"}},
+		TokenUsage: llm.TokenUsage{PromptTokens: 40, CompletionTokens: 10, TotalTokens: 50},
+	}}}
+	engine := NewEngine(adapter, 1, 1)
+	registerAll(t, engine, &testEchoTool{name: "echo"})
+
+	res := runSingleTask(t, engine, &Task{ID: "tb", Input: "loop", Options: TaskOptions{
+		TokenBudget: TokenBudget{MaxTotalTokens: 120},
+	}})
+	if !errors.Is(res.Error, ErrTokenBudgetExceeded) {
+		t.Fatalf("want ErrTokenBudgetExceeded, got %v", res.Error)
+	}
+	if adapter.callCount() != 3 {
+		t.Errorf("budget should trip on the third call, got %d calls", adapter.callCount())
+	}
+	if res.Usage.TotalTokens != 150 {
+		t.Errorf("want cumulative usage 150, got %d", res.Usage.TotalTokens)
+	}
+}
+
+func TestEngine_reportsUsageOnSuccess(t *testing.T) {
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{{
+		Content:    "ok",
+		TokenUsage: llm.TokenUsage{PromptTokens: 7, CompletionTokens: 3},
+	}}}
+	res := runSingleTask(t, NewEngine(adapter, 1, 1), &Task{ID: "u", Input: "hi"})
+	if res.Error != nil {
+		t.Fatalf("unexpected error: %v", res.Error)
+	}
+	if res.Usage.TotalTokens != 10 {
+		t.Errorf("want usage recorded on result, got %+v", res.Usage)
+	}
+}
@@ -37,12 +37,15 @@ type SignedTool interface {
 type ToolRegistry struct {
 	mu       sync.RWMutex
 	tools    map[string]Tool
+	schemas  map[string]toolSchemas
 	verifier security.ToolVerifier
+	policy   *CapabilityPolicy
 }
 
 func NewToolRegistry(verifier security.ToolVerifier) *ToolRegistry {
 	return &ToolRegistry{
 		tools:    make(map[string]Tool),
+		schemas:  make(map[string]toolSchemas),
 		verifier: verifier,
 	}
 }
@@ -53,6 +56,20 @@ func (r *ToolRegistry) SetVerifier(verifier security.ToolVerifier) {
 	r.verifier = verifier
 }
 
+// SetCapabilityPolicy restricts the capabilities tools may declare at registration.
+func (r *ToolRegistry) SetCapabilityPolicy(p *CapabilityPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.policy = p
+}
+
+// CheckCapabilities validates a manifest against the registry's capability policy.
+func (r *ToolRegistry) CheckCapabilities(m *llm.ToolManifest) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.policy.Check(m)
+}
+
 func (r *ToolRegistry) Register(t Tool) error {
 	if t == nil {
 		return ErrNilTool
@@ -66,6 +83,16 @@ func (r *ToolRegistry) Register(t Tool) error {
 		return fmt.Errorf("%w: %s", ErrToolRegistered, m.Name)
 	}
 
+	if err := r.policy.Check(&m); err != nil {
+		slog.Warn("tool_capability_denied", slog.String("tool", m.Name), slog.String("error", err.Error()))
+		return err
+	}
+
+	schemas, err := compileToolSchemas(&m)
+	if err != nil {
+		return err
+	}
+
 	if r.verifier != nil {
 		signedTool, ok := t.(SignedTool)
 		if !ok {
@@ -91,9 +118,27 @@ func (r *ToolRegistry) Register(t Tool) error {
 		}
 	}
 	r.tools[m.Name] = t
+	r.schemas[m.Name] = schemas
 	return nil
 }
 
+// ValidateArguments checks raw tool-call arguments against the tool's declared
+// parameter schema. Tools without a schema accept anything.
+func (r *ToolRegistry) ValidateArguments(name, args string) error {
+	r.mu.RLock()
+	s := r.schemas[name].params
+	r.mu.RUnlock()
+	return validateAgainst(s, name, false, args)
+}
+
+// ValidateOutput checks a tool's output against its declared output schema.
+func (r *ToolRegistry) ValidateOutput(name, output string) error {
+	r.mu.RLock()
+	s := r.schemas[name].output
+	r.mu.RUnlock()
+	return validateAgainst(s, name, true, output)
+}
+
 func (r *ToolRegistry) Get(name string) (Tool, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
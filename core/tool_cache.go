@@ -0,0 +1,246 @@
+package core
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+const (
+	defaultToolCacheEntries = 1024
+	defaultToolCacheBytes   = 16 << 20
+)
+
+// ToolCacheConfig bounds a ToolCache. Zero fields take the defaults.
+type ToolCacheConfig struct {
+	MaxEntries int
+	MaxBytes   int // total size of cached outputs
+}
+
+// ToolCacheCounters are hit/miss metrics for one tool or the whole cache.
+type ToolCacheCounters struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// ToolCacheStats is a snapshot of cache occupancy and metrics.
+type ToolCacheStats struct {
+	ToolCacheCounters
+	Evictions uint64 // entries dropped to respect the size bounds
+	Entries   int
+	Bytes     int
+	ByTool    map[string]ToolCacheCounters
+}
+
+// ToolCache is an LRU cache of tool outputs keyed by tool name and canonical
+// arguments. Only tools whose manifest sets CacheTTLMs are cached, and only
+// successful, validated outputs are stored. It is safe for concurrent use and
+// may be shared between engines.
+type ToolCache struct {
+	mu      sync.Mutex
+	cfg     ToolCacheConfig
+	lru     *list.List // front is most recently used
+	entries map[string]*list.Element
+	bytes   int
+	stats   ToolCacheStats
+}
+
+type toolCacheEntry struct {
+	key     string
+	tool    string
+	output  string
+	expires time.Time
+}
+
+// NewToolCache creates an empty cache with the given bounds.
+func NewToolCache(cfg ToolCacheConfig) *ToolCache {
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = defaultToolCacheEntries
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = defaultToolCacheBytes
+	}
+	return &ToolCache{
+		cfg:     cfg,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+		stats:   ToolCacheStats{ByTool: make(map[string]ToolCacheCounters)},
+	}
+}
+
+// WithToolCache replaces the engine's tool cache, e.g. to share one between
+// engines or change its bounds. A nil cache disables caching.
+func (e *Engine) WithToolCache(c *ToolCache) *Engine {
+	e.toolCache = c
+	return e
+}
+
+// ToolCache returns the engine's tool cache for invalidation and metrics.
+func (e *Engine) ToolCache() *ToolCache {
+	return e.toolCache
+}
+
+// Get returns the cached output of tool for args, if present and fresh.
+func (c *ToolCache) Get(tool, args string) (string, bool) {
+	key := toolCacheKey(tool, args)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counters := c.stats.ByTool[tool]
+	el, ok := c.entries[key]
+	if ok && time.Now().After(el.Value.(*toolCacheEntry).expires) {
+		c.remove(el)
+		ok = false
+	}
+	if !ok {
+		counters.Misses++
+		c.stats.Misses++
+		c.stats.ByTool[tool] = counters
+		return "", false
+	}
+	counters.Hits++
+	c.stats.Hits++
+	c.stats.ByTool[tool] = counters
+	c.lru.MoveToFront(el)
+	return el.Value.(*toolCacheEntry).output, true
+}
+
+// Put stores the output of tool for args for ttl. Outputs larger than the
+// cache's byte bound are not stored.
+func (c *ToolCache) Put(tool, args, output string, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	key := toolCacheKey(tool, args)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+	if len(output) > c.cfg.MaxBytes {
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&toolCacheEntry{key: key, tool: tool, output: output, expires: time.Now().Add(ttl)})
+	c.bytes += len(output)
+	for c.lru.Len() > c.cfg.MaxEntries || c.bytes > c.cfg.MaxBytes {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+// Invalidate drops every cached result of tool and returns how many were removed.
+func (c *ToolCache) Invalidate(tool string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for el := c.lru.Front(); el != nil; {
+		next := el.Next()
+		if el.Value.(*toolCacheEntry).tool == tool {
+			c.remove(el)
+			n++
+		}
+		el = next
+	}
+	return n
+}
+
+// Purge drops every cached result. Metrics are kept.
+func (c *ToolCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Init()
+	c.entries = make(map[string]*list.Element)
+	c.bytes = 0
+}
+
+// Stats returns a snapshot of the cache's metrics.
+func (c *ToolCache) Stats() ToolCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Entries = c.lru.Len()
+	s.Bytes = c.bytes
+	s.ByTool = make(map[string]ToolCacheCounters, len(c.stats.ByTool))
+	for k, v := range c.stats.ByTool {
+		s.ByTool[k] = v
+	}
+	return s
+}
+
+// resolveCached serves tools that opt into caching from the engine's tool
+// cache and otherwise falls through to resolveTool. It runs innermost in the
+// tool interceptor chain, so cached outputs are still approved, audited and
+// screened by the prompt guard exactly like fresh ones. The capability policy
+// is checked before the lookup: it may have been narrowed since the output was
+// cached, or the cache may be shared with a more permissive engine.
+func (e *Engine) resolveCached(ctx context.Context, call llm.ToolCall) (string, error) {
+	tool, ttl := e.cacheableTool(call.Name)
+	if ttl <= 0 {
+		return e.resolveTool(ctx, call)
+	}
+	manifest := tool.Manifest()
+	if capErr := e.tools.CheckCapabilities(&manifest); capErr != nil {
+		WithComponent("tool_cache").Warn("tool_capability_denied",
+			slog.String("tool_name", call.Name), slog.String("error", capErr.Error()))
+		return "", capErr
+	}
+	if out, ok := e.toolCache.Get(call.Name, call.Arguments); ok {
+		WithComponent("tool_cache").Debug("tool_cache_hit", slog.String("tool_name", call.Name))
+		return out, nil
+	}
+	out, err := e.dispatchLocal(ctx, tool, call)
+	if err == nil {
+		e.toolCache.Put(call.Name, call.Arguments, out, ttl)
+	}
+	return out, err
+}
+
+// cacheableTool returns a local tool and how long its results may be cached;
+// the duration is zero when caching is disabled or the tool has not opted in.
+func (e *Engine) cacheableTool(name string) (Tool, time.Duration) {
+	if e.toolCache == nil {
+		return nil, 0
+	}
+	tool, err := e.tools.Get(name)
+	if err != nil {
+		return nil, 0
+	}
+	return tool, time.Duration(tool.Manifest().CacheTTLMs) * time.Millisecond
+}
+
+func (c *ToolCache) remove(el *list.Element) {
+	entry := c.lru.Remove(el).(*toolCacheEntry)
+	delete(c.entries, entry.key)
+	c.bytes -= len(entry.output)
+}
+
+// toolCacheKey combines the tool name with its canonicalised arguments.
+func toolCacheKey(tool, args string) string {
+	return tool + "\x00" + canonicalArguments(args)
+}
+
+// canonicalArguments re-encodes JSON arguments with sorted object keys and
+// without insignificant whitespace, so that equivalent calls share a cache
+// entry. Arguments that are not valid JSON are used verbatim.
+func canonicalArguments(args string) string {
+	dec := json.NewDecoder(strings.NewReader(args))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return args
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return args
+	}
+	return string(out)
+}
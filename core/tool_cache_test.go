@@ -0,0 +1,159 @@
+package core
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+// cachedTool is a countingTool that opts into result caching.
+type cachedTool struct{ countingTool }
+
+func (c *cachedTool) Manifest() llm.ToolManifest {
+	return llm.ToolManifest{Name: c.name, CacheTTLMs: 60_000}
+}
+
+func TestToolCache_servesIdenticalCallsAndStillGuards(t *testing.T) {
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{
+		{ToolCalls: []llm.ToolCall{{ID: "c1", Name: "lookup", Arguments: `{"a": 1, "b": "x"}`}}},
+		{ToolCalls: []llm.ToolCall{{ID: "c2", Name: "lookup", Arguments: `{"b":"x","a":1}`}}},
+		{Content: "done"},
+	}}
+	var transcript bytes.Buffer
+	engine := NewEngine(adapter, 1, 1).WithTranscript(NewTranscriptWriter(&transcript))
+	tool := &cachedTool{countingTool{name: "lookup"}}
+	registerAll(t, engine, tool)
+
+	if res := runSingleTask(t, engine, &Task{ID: "cache", Input: "go"}); res.Error != nil {
+		t.Fatalf("unexpected error: %v", res.Error)
+	}
+	if tool.count() != 1 {
+		t.Errorf("equivalent arguments should hit the cache, tool ran %d times", tool.count())
+	}
+	if got := lastToolResult(t, adapter); got.Content != "lookup ran" {
+		t.Errorf("cached output should reach the model, got %+v", got)
+	}
+	stats := engine.ToolCache().Stats()
+	if stats.Hits != 1 || stats.Misses != 1 || stats.ByTool["lookup"].Hits != 1 || stats.Entries != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	records, err := ReadTranscript(&transcript)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scans := 0
+	for _, rec := range records {
+		if rec.Type == TranscriptGuard && rec.Guard.Stage == GuardStageToolOutput {
+			scans++
+		}
+	}
+	if scans != 2 {
+		t.Errorf("cache hits must still be screened by the guard, got %d scans", scans)
+	}
+
+	if n := engine.ToolCache().Invalidate("lookup"); n != 1 {
+		t.Errorf("Invalidate removed %d entries, want 1", n)
+	}
+	if _, ok := engine.ToolCache().Get("lookup", `{"a":1,"b":"x"}`); ok {
+		t.Error("invalidated entry should be gone")
+	}
+}
+
+func TestToolCache_uncachedToolsAlwaysRun(t *testing.T) {
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{
+		{ToolCalls: []llm.ToolCall{{ID: "c1", Name: "count"}, {ID: "c2", Name: "count"}}},
+		{Content: "done"},
+	}}
+	engine := NewEngine(adapter, 1, 1)
+	tool := &countingTool{name: "count"}
+	registerAll(t, engine, tool)
+
+	runSingleTask(t, engine, &Task{ID: "nocache", Input: "go"})
+	if tool.count() != 2 {
+		t.Errorf("tools without CacheTTLMs must not be cached, ran %d times", tool.count())
+	}
+	if stats := engine.ToolCache().Stats(); stats.Hits+stats.Misses != 0 {
+		t.Errorf("uncached tools should not touch the cache, got %+v", stats)
+	}
+}
+
+func TestToolCache_boundsAndExpiry(t *testing.T) {
+	c := NewToolCache(ToolCacheConfig{MaxEntries: 2, MaxBytes: 10})
+	c.Put("t", `{"k":1}`, "aaaa", time.Minute)
+	c.Put("t", `{"k":2}`, "bbbb", time.Minute)
+	c.Get("t", `{"k":1}`) // k=1 becomes most recently used
+	c.Put("t", `{"k":3}`, "cccc", time.Minute)
+	if _, ok := c.Get("t", `{"k":2}`); ok {
+		t.Error("least recently used entry should be evicted")
+	}
+	if _, ok := c.Get("t", `{"k":1}`); !ok {
+		t.Error("recently used entry should survive")
+	}
+
+	c.Put("u", "{}", "0123456789", time.Minute)
+	if s := c.Stats(); s.Bytes > 10 || s.Entries != 1 {
+		t.Errorf("byte bound not respected: %+v", s)
+	}
+	c.Put("u", "[]", strings.Repeat("x", 11), time.Minute)
+	if _, ok := c.Get("u", "[]"); ok {
+		t.Error("outputs larger than MaxBytes must not be cached")
+	}
+
+	c.Put("v", "{}", "v", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if _, ok := c.Get("v", "{}"); ok {
+		t.Error("expired entry should miss")
+	}
+	if s := c.Stats(); s.Evictions == 0 || s.ByTool["v"].Misses != 1 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
+
+func TestCanonicalArguments(t *testing.T) {
+	cases := map[string]string{
+		`{ "b": [1, 2.50], "a": {"z": null, "y": true} }`: `{"a":{"y":true,"z":null},"b":[1,2.50]}`,
+		`not json`: `not json`,
+		`{} {}`:    `{} {}`,
+	}
+	for in, want := range cases {
+		if got := canonicalArguments(in); got != want {
+			t.Errorf("canonicalArguments(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+// netCachedTool is a cachedTool that needs network access.
+type netCachedTool struct{ cachedTool }
+
+func (n *netCachedTool) Manifest() llm.ToolManifest {
+	m := n.cachedTool.Manifest()
+	m.Capabilities = []llm.Capability{llm.CapNetwork}
+	return m
+}
+
+func TestToolCache_hitsAreStillSubjectToCapabilityPolicy(t *testing.T) {
+	call := []llm.ToolCall{{ID: "c1", Name: "fetch", Arguments: `{"url":"x"}`}}
+	cache := NewToolCache(ToolCacheConfig{})
+	tool := &netCachedTool{cachedTool{countingTool{name: "fetch"}}}
+
+	open := NewEngine(&scriptedLLM{responses: []llm.LLMResponse{{ToolCalls: call}, {Content: "done"}}}, 1, 1).WithToolCache(cache)
+	registerAll(t, open, tool)
+	runSingleTask(t, open, &Task{ID: "open", Input: "go"})
+
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{{ToolCalls: call}, {Content: "done"}}}
+	strict := NewEngine(adapter, 1, 1).WithToolCache(cache)
+	registerAll(t, strict, tool)
+	strict.WithCapabilityPolicy(NewCapabilityPolicy())
+	runSingleTask(t, strict, &Task{ID: "strict", Input: "go"})
+
+	if got := lastToolResult(t, adapter); !got.IsError || !strings.Contains(got.Content, ErrCapabilityDenied.Error()) {
+		t.Errorf("a cached output must not bypass the capability policy, got %+v", got)
+	}
+	if tool.count() != 1 || cache.Stats().Hits != 0 {
+		t.Errorf("want one execution and no cache hits, got %d runs and %+v", tool.count(), cache.Stats())
+	}
+}
@@ -0,0 +1,180 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+// defaultToolParallelism bounds concurrent tool calls per task when
+// TaskOptions.MaxParallelTools is unset.
+const defaultToolParallelism = 4
+
+// toolBatch tracks one model turn's tool calls while they execute.
+type toolBatch struct {
+	results []llm.ToolResultMessage
+	wg      sync.WaitGroup
+	sem     chan struct{}
+	cancel  context.CancelFunc
+
+	fatalOnce sync.Once
+	fatal     error
+
+	mu      sync.Mutex
+	running []string // names of the tools executing right now, in start order
+}
+
+// toolStarted marks the task as waiting on name.
+func (b *toolBatch) toolStarted(t *Task, iteration int, name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.running = append(b.running, name)
+	t.setStatus(TaskWaitingTool, iteration, name)
+}
+
+// toolFinished marks the task as waiting on the oldest tool still executing,
+// or as running again once the last one finished.
+func (b *toolBatch) toolFinished(t *Task, iteration int, name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if i := slices.Index(b.running, name); i >= 0 {
+		b.running = slices.Delete(b.running, i, i+1)
+	}
+	if len(b.running) > 0 {
+		t.setStatus(TaskWaitingTool, iteration, b.running[0])
+		return
+	}
+	t.setStatus(TaskRunning, iteration, "")
+}
+
+// abort records the first fatal error and cancels all sibling calls.
+func (b *toolBatch) abort(err error) {
+	b.fatalOnce.Do(func() {
+		b.fatal = err
+		b.cancel()
+	})
+}
+
+// runToolCalls executes every tool call of a model turn and returns their
+// results in the original call order. Calls run concurrently up to the task's
+// parallelism limit; a tool whose manifest sets NoParallel runs alone, after
+// all earlier calls finish and before any later call starts. A fatal error
+// from any call (see isFatalToolError) cancels its siblings and is returned.
+func (e *Engine) runToolCalls(ctx context.Context, t *Task, iteration int, calls []llm.ToolCall) ([]llm.ToolResultMessage, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	batch := &toolBatch{
+		results: make([]llm.ToolResultMessage, len(calls)),
+		sem:     make(chan struct{}, t.Options.maxParallelTools()),
+		cancel:  cancel,
+	}
+
+	for i, call := range calls {
+		if ctx.Err() != nil {
+			break
+		}
+		exclusive := e.toolIsExclusive(call.Name)
+		if exclusive {
+			batch.wg.Wait()
+		}
+
+		select {
+		case batch.sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		batch.wg.Add(1)
+		go func() {
+			defer batch.wg.Done()
+			defer func() { <-batch.sem }()
+			batch.results[i] = e.runToolCall(ctx, t, iteration, call, batch)
+		}()
+
+		if exclusive {
+			batch.wg.Wait()
+		}
+	}
+	batch.wg.Wait()
+
+	if batch.fatal != nil {
+		return nil, batch.fatal
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return batch.results, nil
+}
+
+// runToolCall executes a single call and converts its outcome into a result message.
+func (e *Engine) runToolCall(ctx context.Context, t *Task, iteration int, call llm.ToolCall, batch *toolBatch) llm.ToolResultMessage {
+	t.emit(ctx.Done(), TaskEvent{Type: EventToolCall, Iteration: iteration, ToolCall: call})
+
+	start := time.Now()
+	toolResult, execErr := e.executeToolCall(ctx, t, iteration, call, batch)
+	if isFatalToolError(execErr) {
+		batch.abort(execErr)
+	}
+	e.record(&TranscriptRecord{
+		Type: TranscriptTool, TaskID: t.ID, Iteration: iteration, ToolCall: &call, ToolResult: &toolResult,
+		Error: errorString(execErr), ErrorKinds: errorKinds(execErr), DurationMs: time.Since(start).Milliseconds(),
+	})
+
+	t.emit(ctx.Done(), TaskEvent{Type: EventToolResult, Iteration: iteration, ToolCall: call, ToolResult: toolResult})
+	return toolResult
+}
+
+// isFatalToolError reports whether a tool error must abort the task rather than
+// be reported to the model: security violations, replay divergence, and an
+// unreachable sandbox, which the model cannot work around but a task retry can.
+func isFatalToolError(err error) bool {
+	return err != nil && (strings.Contains(err.Error(), "security_violation") ||
+		errors.Is(err, ErrReplayDiverged) || errors.Is(err, ErrSandboxUnavailable))
+}
+
+// executeToolCall runs a permitted call, or serves its recorded result in
+// replay mode. Execution errors are folded into the returned message and
+// also returned so the caller can apply batch semantics.
+func (e *Engine) executeToolCall(ctx context.Context, t *Task, iteration int, call llm.ToolCall, batch *toolBatch) (llm.ToolResultMessage, error) {
+	if e.replay != nil {
+		return e.replay.toolResult(iteration, call)
+	}
+
+	var (
+		result  string
+		execErr error
+	)
+	if t.Options.toolAllowed(call.Name) {
+		batch.toolStarted(t, iteration, call.Name)
+		result, execErr = e.dispatchTool(ctx, t.ID, iteration, call)
+		batch.toolFinished(t, iteration, call.Name)
+	} else {
+		execErr = fmt.Errorf("%w: %s", ErrToolNotPermitted, call.Name)
+	}
+
+	toolResult := llm.ToolResultMessage{ToolCallID: call.ID, Content: result}
+	if execErr != nil {
+		toolResult.Content = toolErrorContent(execErr)
+		toolResult.IsError = true
+	}
+	return toolResult, execErr
+}
+
+// toolIsExclusive reports whether a registered tool opted out of parallel execution.
+func (e *Engine) toolIsExclusive(name string) bool {
+	tool, err := e.tools.Get(name)
+	if err != nil {
+		return false
+	}
+	return tool.Manifest().NoParallel
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+var (
+	ErrCapabilityDenied = errors.New("tool capability not granted")
+	ErrToolTimeout      = errors.New("tool exceeded its declared max runtime")
+)
+
+// CapabilityPolicy is the set of capabilities an engine grants to in-process
+// (Layer 0) tools. A nil policy grants everything, preserving the historical
+// behaviour for engines that never configure one.
+type CapabilityPolicy struct {
+	granted map[llm.Capability]struct{}
+}
+
+// NewCapabilityPolicy builds a policy granting exactly the given capabilities.
+func NewCapabilityPolicy(granted ...llm.Capability) *CapabilityPolicy {
+	p := &CapabilityPolicy{granted: make(map[llm.Capability]struct{}, len(granted))}
+	for _, c := range granted {
+		p.granted[c] = struct{}{}
+	}
+	return p
+}
+
+// Check returns an error wrapping ErrCapabilityDenied if the manifest declares
+// any capability the policy does not grant.
+func (p *CapabilityPolicy) Check(m *llm.ToolManifest) error {
+	if p == nil {
+		return nil
+	}
+	var missing []string
+	for _, c := range m.Capabilities {
+		if _, ok := p.granted[c]; !ok {
+			missing = append(missing, string(c))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s requires %s", ErrCapabilityDenied, m.Name, strings.Join(missing, ","))
+	}
+	return nil
+}
+
+// WithCapabilityPolicy restricts which declared capabilities in-process tools
+// may hold. Tools violating the policy are refused at registration and, for
+// tools registered before the policy was attached, at dispatch.
+func (e *Engine) WithCapabilityPolicy(p *CapabilityPolicy) *Engine {
+	e.tools.SetCapabilityPolicy(p)
+	return e
+}
+
+// executeBounded runs tool.Execute under a deadline derived from the manifest's
+// MaxRuntimeMs. A tool that ignores its context is abandoned once the deadline
+// passes: the call returns ErrToolTimeout and the tool goroutine is left to
+// finish on its own, its late result discarded.
+func executeBounded(ctx context.Context, tool Tool, m *llm.ToolManifest, args string) (string, error) {
+	if m.MaxRuntimeMs <= 0 {
+		return tool.Execute(ctx, args)
+	}
+
+	limit := time.Duration(m.MaxRuntimeMs) * time.Millisecond
+	ctx, cancel := context.WithTimeout(ctx, limit)
+	defer cancel()
+
+	type outcome struct {
+		out string
+		err error
+	}
+	done := make(chan outcome, 1) // buffered so an abandoned tool never blocks
+	go func() {
+		out, err := tool.Execute(ctx, args)
+		done <- outcome{out: out, err: err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.out, o.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("%w: %s did not finish within %dms", ErrToolTimeout, m.Name, m.MaxRuntimeMs)
+		}
+		return "", ctx.Err()
+	}
+}
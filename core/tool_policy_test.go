@@ -0,0 +1,122 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+// stubbornTool ignores its context and sleeps past any deadline.
+type stubbornTool struct {
+	maxRuntimeMs int
+	sleep        time.Duration
+	caps         []llm.Capability
+}
+
+func (s *stubbornTool) Manifest() llm.ToolManifest {
+	return llm.ToolManifest{Name: "stubborn", MaxRuntimeMs: s.maxRuntimeMs, Capabilities: s.caps}
+}
+
+func (s *stubbornTool) Execute(_ context.Context, _ string) (string, error) {
+	time.Sleep(s.sleep)
+	return "finally", nil
+}
+
+func TestExecuteBounded_abandonsToolThatIgnoresContext(t *testing.T) {
+	tool := &stubbornTool{maxRuntimeMs: 20, sleep: 500 * time.Millisecond}
+	m := tool.Manifest()
+
+	start := time.Now()
+	_, err := executeBounded(context.Background(), tool, &m, "{}")
+	if !errors.Is(err, ErrToolTimeout) {
+		t.Fatalf("want ErrToolTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("tool was not abandoned promptly (took %s)", elapsed)
+	}
+}
+
+func TestExecuteBounded_withinBudget(t *testing.T) {
+	tool := &stubbornTool{maxRuntimeMs: 500, sleep: time.Millisecond}
+	m := tool.Manifest()
+	out, err := executeBounded(context.Background(), tool, &m, "{}")
+	if err != nil || out != "finally" {
+		t.Fatalf("want output within budget, got %q (err=%v)", out, err)
+	}
+}
+
+func TestEngine_toolTimeoutReturnedToModel(t *testing.T) {
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{
+		{ToolCalls: []llm.ToolCall{{ID: "c1", Name: "stubborn", Arguments: "{}"}}},
+		{Content: "gave up"},
+	}}
+	engine := NewEngine(adapter, 1, 1)
+	registerAll(t, engine, &stubbornTool{maxRuntimeMs: 10, sleep: 200 * time.Millisecond})
+
+	res := runSingleTask(t, engine, &Task{ID: "tt", Input: "go"})
+	if res.Error != nil {
+		t.Fatalf("unexpected error: %v", res.Error)
+	}
+	results := adapter.requests[1][len(adapter.requests[1])-1].ToolResults
+	if !results[0].IsError || !strings.Contains(results[0].Content, ErrToolTimeout.Error()) {
+		t.Errorf("want timeout error result, got %+v", results[0])
+	}
+}
+
+func TestCapabilityPolicy_refusesRegistration(t *testing.T) {
+	engine := NewEngine(&MockLLMAdapter{}, 1, 1).WithCapabilityPolicy(NewCapabilityPolicy(llm.CapState))
+
+	err := engine.RegisterTool(&stubbornTool{caps: []llm.Capability{llm.CapState, llm.CapNetwork}})
+	if !errors.Is(err, ErrCapabilityDenied) {
+		t.Fatalf("want ErrCapabilityDenied, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "network") {
+		t.Errorf("error should name the missing capability: %v", err)
+	}
+	if err := engine.RegisterTool(&MockSysInfoTool{}); err != nil {
+		t.Errorf("tool without capabilities should register: %v", err)
+	}
+}
+
+func TestCapabilityPolicy_refusesDispatch(t *testing.T) {
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{
+		{ToolCalls: []llm.ToolCall{{ID: "c1", Name: "stubborn", Arguments: "{}"}}},
+		{Content: "ok"},
+	}}
+	al := &MockAuditLogger{}
+	engine := NewEngine(adapter, 1, 1).WithAuditLogger(al)
+	registerAll(t, engine, &stubbornTool{caps: []llm.Capability{llm.CapFilesystem}})
+	// Policy attached after registration must still be enforced at dispatch.
+	engine.WithCapabilityPolicy(NewCapabilityPolicy())
+
+	res := runSingleTask(t, engine, &Task{ID: "cd", Input: "go"})
+	if res.Error != nil {
+		t.Fatalf("unexpected error: %v", res.Error)
+	}
+	results := adapter.requests[1][len(adapter.requests[1])-1].ToolResults
+	if !results[0].IsError || !strings.Contains(results[0].Content, ErrCapabilityDenied.Error()) {
+		t.Errorf("want capability error result, got %+v", results[0])
+	}
+
+	found := false
+	for _, ev := range al.Events {
+		if ev.Type == "AUDIT_TOOL_DENIED" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected AUDIT_TOOL_DENIED event")
+	}
+}
+
+func TestCapabilityPolicy_nilGrantsEverything(t *testing.T) {
+	var p *CapabilityPolicy
+	m := llm.ToolManifest{Name: "x", Capabilities: []llm.Capability{llm.CapNetwork}}
+	if err := p.Check(&m); err != nil {
+		t.Fatalf("nil policy should allow all: %v", err)
+	}
+}
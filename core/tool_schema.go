@@ -0,0 +1,123 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fzihak/aethercore/core/llm"
+	"github.com/fzihak/aethercore/core/schema"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+var (
+	ErrInvalidToolArguments = errors.New("invalid tool arguments")
+	ErrInvalidToolOutput    = errors.New("invalid tool output")
+)
+
+// toolSchemas holds the compiled parameter and output schemas of one tool.
+// A nil field means the tool declared no schema for that side.
+type toolSchemas struct {
+	params *schema.Schema
+	output *schema.Schema
+}
+
+// compileToolSchemas compiles the schemas declared in a manifest.
+func compileToolSchemas(m *llm.ToolManifest) (toolSchemas, error) {
+	var ts toolSchemas
+	if len(m.Parameters) > 0 {
+		s, err := schema.Compile(m.Parameters)
+		if err != nil {
+			return ts, fmt.Errorf("tool %s parameters: %w", m.Name, err)
+		}
+		ts.params = s
+	}
+	if len(m.OutputSchema) > 0 {
+		s, err := schema.Compile(m.OutputSchema)
+		if err != nil {
+			return ts, fmt.Errorf("tool %s output_schema: %w", m.Name, err)
+		}
+		ts.output = s
+	}
+	return ts, nil
+}
+
+// ToolSchemaError reports tool-call arguments or tool output that do not
+// conform to the schema declared in the tool's manifest.
+type ToolSchemaError struct {
+	Tool       string
+	Output     bool // false: arguments were invalid; true: the tool's output was
+	Violations []schema.Violation
+}
+
+func (e *ToolSchemaError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, v.Path+": "+v.Message)
+	}
+	return fmt.Sprintf("%s for %s: %s", e.Unwrap().Error(), e.Tool, strings.Join(parts, "; "))
+}
+
+func (e *ToolSchemaError) Unwrap() error {
+	if e.Output {
+		return ErrInvalidToolOutput
+	}
+	return ErrInvalidToolArguments
+}
+
+// Feedback renders the error as the structured tool result returned to the
+// model, so it can correct its call without the tool having run.
+func (e *ToolSchemaError) Feedback() string {
+	kind, hint := "invalid_tool_arguments", "Fix the arguments to match the tool's parameter schema and call the tool again."
+	if e.Output {
+		kind, hint = "invalid_tool_output", "The tool returned output that does not match its declared schema; do not rely on it."
+	}
+	raw, err := json.Marshal(struct {
+		Error      string             `json:"error"`
+		Tool       string             `json:"tool"`
+		Violations []schema.Violation `json:"violations"`
+		Hint       string             `json:"hint"`
+	}{kind, e.Tool, e.Violations, hint})
+	if err != nil {
+		return e.Error()
+	}
+	return string(raw)
+}
+
+// validateAgainst checks doc against s and converts failures to a ToolSchemaError.
+func validateAgainst(s *schema.Schema, toolName string, output bool, doc string) error {
+	if s == nil {
+		return nil
+	}
+	if !output && strings.TrimSpace(doc) == "" {
+		doc = "{}" // models commonly omit arguments for parameterless tools
+	}
+
+	err := s.Validate([]byte(doc))
+	if err == nil {
+		return nil
+	}
+	var ve *schema.ValidationError
+	if errors.As(err, &ve) {
+		return &ToolSchemaError{Tool: toolName, Output: output, Violations: ve.Violations}
+	}
+	msg := "arguments are not valid JSON"
+	if output {
+		msg = "output is not valid JSON"
+	}
+	return &ToolSchemaError{Tool: toolName, Output: output, Violations: []schema.Violation{
+		{Path: "$", Message: msg},
+	}}
+}
+
+// toolErrorContent renders a tool failure for the model. Schema failures are
+// returned as structured JSON; everything else as the plain error text.
+func toolErrorContent(err error) string {
+	var se *ToolSchemaError
+	if errors.As(err, &se) {
+		return se.Feedback()
+	}
+	return err.Error()
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/fzihak/aethercore/core/llm"
+	"github.com/fzihak/aethercore/core/schema"
+)
+
+// schemaTool declares parameter and output schemas and returns a fixed output.
+type schemaTool struct {
+	output string
+	calls  atomic.Int32
+}
+
+func (s *schemaTool) Manifest() llm.ToolManifest {
+	return llm.ToolManifest{
+		Name:         "lookup",
+		Parameters:   json.RawMessage(`{"type":"object","properties":{"id":{"type":"integer","minimum":1}},"required":["id"]}`),
+		OutputSchema: json.RawMessage(`{"type":"object","properties":{"name":{"type":"string"}},"required":["name"]}`),
+	}
+}
+
+func (s *schemaTool) Execute(_ context.Context, _ string) (string, error) {
+	s.calls.Add(1)
+	return s.output, nil
+}
+
+func TestToolSchema_invalidArgumentsFedBackWithoutExecution(t *testing.T) {
+	tool := &schemaTool{output: `{"name":"ada"}`}
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{
+		{ToolCalls: []llm.ToolCall{{ID: "c1", Name: "lookup", Arguments: `{"id":"seven"}`}}},
+		{ToolCalls: []llm.ToolCall{{ID: "c2", Name: "lookup", Arguments: `{"id":7}`}}},
+		{Content: "ada"},
+	}}
+	engine := NewEngine(adapter, 1, 1)
+	registerAll(t, engine, tool)
+
+	res := runSingleTask(t, engine, &Task{ID: "sch", Input: "find 7"})
+	if res.Error != nil {
+		t.Fatalf("unexpected error: %v", res.Error)
+	}
+	if tool.calls.Load() != 1 {
+		t.Errorf("tool should only run for the corrected call, ran %d times", tool.calls.Load())
+	}
+
+	first := adapter.requests[1][len(adapter.requests[1])-1].ToolResults[0]
+	if !first.IsError {
+		t.Fatal("invalid arguments must produce an error result")
+	}
+	var feedback struct {
+		Error      string             `json:"error"`
+		Tool       string             `json:"tool"`
+		Violations []schema.Violation `json:"violations"`
+	}
+	if err := json.Unmarshal([]byte(first.Content), &feedback); err != nil {
+		t.Fatalf("feedback should be structured JSON: %v (%s)", err, first.Content)
+	}
+	if feedback.Error != "invalid_tool_arguments" || feedback.Tool != "lookup" ||
+		len(feedback.Violations) != 1 || feedback.Violations[0].Path != "$.id" {
+		t.Errorf("unexpected feedback: %+v", feedback)
+	}
+
+	second := adapter.requests[2][len(adapter.requests[2])-1].ToolResults[0]
+	if second.IsError || second.Content != `{"name":"ada"}` {
+		t.Errorf("corrected call should succeed, got %+v", second)
+	}
+}
+
+func TestToolSchema_invalidOutputRejected(t *testing.T) {
+	tool := &schemaTool{output: `{"nom":"ada"}`}
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{
+		{ToolCalls: []llm.ToolCall{{ID: "c1", Name: "lookup", Arguments: `{"id":1}`}}},
+		{Content: "unknown"},
+	}}
+	engine := NewEngine(adapter, 1, 1)
+	registerAll(t, engine, tool)
+
+	res := runSingleTask(t, engine, &Task{ID: "out", Input: "find 1"})
+	if res.Error != nil {
+		t.Fatalf("unexpected error: %v", res.Error)
+	}
+	result := adapter.requests[1][len(adapter.requests[1])-1].ToolResults[0]
+	if !result.IsError {
+		t.Fatalf("output violating its schema must be an error result, got %+v", result)
+	}
+	var feedback struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(result.Content), &feedback); err != nil || feedback.Error != "invalid_tool_output" {
+		t.Errorf("unexpected feedback %q (err=%v)", result.Content, err)
+	}
+}
+
+func TestToolRegistry_ValidateArguments(t *testing.T) {
+	r := NewToolRegistry(nil)
+	if err := r.Register(&schemaTool{}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := r.ValidateArguments("lookup", `{"id":3}`); err != nil {
+		t.Errorf("valid args rejected: %v", err)
+	}
+	if err := r.ValidateArguments("lookup", `{"id":`); !errors.Is(err, ErrInvalidToolArguments) {
+		t.Errorf("malformed args: want ErrInvalidToolArguments, got %v", err)
+	}
+	if err := r.ValidateArguments("unknown", `anything`); err != nil {
+		t.Errorf("tools without a schema accept anything: %v", err)
+	}
+
+	var se *ToolSchemaError
+	if err := r.ValidateOutput("lookup", `not json`); !errors.As(err, &se) || se.Violations[0].Message != "output is not valid JSON" {
+		t.Errorf("malformed output: unexpected error %v", err)
+	}
+}
+
+type badSchemaTool struct{}
+
+func (b *badSchemaTool) Manifest() llm.ToolManifest {
+	return llm.ToolManifest{Name: "bad", Parameters: json.RawMessage(`{"type":"strng"}`)}
+}
+func (b *badSchemaTool) Execute(_ context.Context, _ string) (string, error) { return "", nil }
+
+func TestToolRegistry_rejectsInvalidSchema(t *testing.T) {
+	r := NewToolRegistry(nil)
+	if err := r.Register(&badSchemaTool{}); !errors.Is(err, schema.ErrInvalidSchema) {
+		t.Fatalf("want ErrInvalidSchema, got %v", err)
+	}
+}
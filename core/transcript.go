@@ -0,0 +1,217 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fzihak/aethercore/core/llm"
+	"github.com/fzihak/aethercore/core/security"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+// TranscriptRecordType classifies a transcript record.
+type TranscriptRecordType string
+
+const (
+	TranscriptTask   TranscriptRecordType = "task"   // task definition, recorded when a worker picks it up
+	TranscriptGuard  TranscriptRecordType = "guard"  // prompt-guard verdict on user input or tool output
+	TranscriptLLM    TranscriptRecordType = "llm"    // one LLM call: prompt, response and latency
+	TranscriptTool   TranscriptRecordType = "tool"   // one tool call and the result fed back to the model
+	TranscriptResult TranscriptRecordType = "result" // final task outcome
+)
+
+// Guard stages recorded in GuardVerdict.Stage.
+const (
+	GuardStageInput      = "input"
+	GuardStageToolOutput = "tool_output"
+)
+
+// GuardVerdict is the recorded outcome of a prompt-guard scan.
+type GuardVerdict struct {
+	Stage      string                      `json:"stage"`
+	Tool       string                      `json:"tool,omitempty"`
+	Safe       bool                        `json:"safe"`
+	Confidence float64                     `json:"confidence,omitempty"`
+	Violations []security.AdversarialMatch `json:"violations,omitempty"`
+}
+
+// TranscriptRecord is one line of a task transcript.
+type TranscriptRecord struct {
+	Type       TranscriptRecordType   `json:"type"`
+	TaskID     string                 `json:"task_id"`
+	ParentID   string                 `json:"parent_task_id,omitempty"` // set for delegated sub-agents
+	Timestamp  time.Time              `json:"ts"`
+	Iteration  int                    `json:"iteration,omitempty"`
+	Task       *JournaledTask         `json:"task,omitempty"`        // TranscriptTask
+	Guard      *GuardVerdict          `json:"guard,omitempty"`       // TranscriptGuard
+	Messages   []llm.Message          `json:"messages,omitempty"`    // TranscriptLLM: prompt as sent
+	Response   *llm.LLMResponse       `json:"response,omitempty"`    // TranscriptLLM
+	ToolCall   *llm.ToolCall          `json:"tool_call,omitempty"`   // TranscriptTool
+	ToolResult *llm.ToolResultMessage `json:"tool_result,omitempty"` // TranscriptTool
+	Output     string                 `json:"output,omitempty"`      // TranscriptResult
+	Error      string                 `json:"error,omitempty"`
+	ErrorKinds []string               `json:"error_kinds,omitempty"` // sentinels Error wraps, restored on replay
+	DurationMs int64                  `json:"duration_ms,omitempty"`
+}
+
+// TranscriptRecorder receives transcript records. Implementations must be
+// safe for concurrent use: tool calls of one turn are recorded in parallel.
+type TranscriptRecorder interface {
+	Record(rec *TranscriptRecord) error
+}
+
+// TranscriptWriter is a TranscriptRecorder that writes JSONL to an io.Writer.
+// Records of concurrently running tasks are interleaved; each carries its task ID.
+type TranscriptWriter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	c   io.Closer
+}
+
+// NewTranscriptWriter returns a recorder writing one JSON record per line to w.
+func NewTranscriptWriter(w io.Writer) *TranscriptWriter {
+	return &TranscriptWriter{enc: json.NewEncoder(w)}
+}
+
+// OpenTranscriptFile opens (or creates) a JSONL transcript file for appending.
+func OpenTranscriptFile(path string) (*TranscriptWriter, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("transcript_open_failed: %w", err)
+	}
+	w := NewTranscriptWriter(f)
+	w.c = f
+	return w, nil
+}
+
+// Record writes a single record.
+func (w *TranscriptWriter) Record(rec *TranscriptRecord) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.enc.Encode(rec)
+}
+
+// Close closes the underlying file when the writer was created by OpenTranscriptFile.
+func (w *TranscriptWriter) Close() error {
+	if w.c == nil {
+		return nil
+	}
+	return w.c.Close()
+}
+
+// WithTranscript attaches a recorder that captures every task's messages, LLM
+// responses, tool calls and results, guard verdicts and timings.
+func (e *Engine) WithTranscript(r TranscriptRecorder) *Engine {
+	e.transcript = r
+	return e
+}
+
+// record forwards a transcript record, logging rather than failing the task on error.
+func (e *Engine) record(rec *TranscriptRecord) {
+	if e.transcript == nil {
+		return
+	}
+	rec.Timestamp = time.Now()
+	rec.ParentID = e.parentOf(rec.TaskID)
+	if err := e.transcript.Record(rec); err != nil {
+		WithComponent("transcript").Error("transcript_record_failed",
+			slog.String("task_id", rec.TaskID),
+			slog.String("type", string(rec.Type)),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// recordGuard records a prompt-guard verdict.
+func (e *Engine) recordGuard(taskID, stage, tool string, res *security.GuardResult) {
+	e.record(&TranscriptRecord{Type: TranscriptGuard, TaskID: taskID, Guard: &GuardVerdict{
+		Stage: stage, Tool: tool, Safe: res.IsSafe, Confidence: res.Confidence, Violations: res.Violations,
+	}})
+}
+
+// errorString returns err's message, or "" for a nil error.
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// transcriptErrorKinds names the sentinel errors whose identity survives a
+// transcript round trip. Names are part of the transcript format: append only.
+var transcriptErrorKinds = []struct {
+	kind string
+	err  error
+}{
+	{"context_canceled", context.Canceled},
+	{"deadline_exceeded", context.DeadlineExceeded},
+	{"task_timeout", ErrTaskTimeout},
+	{"task_cancelled", ErrTaskCancelled},
+	{"max_iterations", ErrMaxIterationsExceeded},
+	{"token_budget", ErrTokenBudgetExceeded},
+	{"tool_not_permitted", ErrToolNotPermitted},
+	{"tool_call_denied", ErrToolCallDenied},
+	{"capability_denied", ErrCapabilityDenied},
+	{"tool_timeout", ErrToolTimeout},
+	{"invalid_tool_arguments", ErrInvalidToolArguments},
+	{"invalid_tool_output", ErrInvalidToolOutput},
+	{"invalid_structured_output", ErrInvalidStructuredOutput},
+	{"sandbox_unavailable", ErrSandboxUnavailable},
+	{"delegation_depth", ErrDelegationDepthExceeded},
+	{"invalid_delegation", ErrInvalidDelegation},
+	{"plan_failed", ErrPlanFailed},
+	{"replay_diverged", ErrReplayDiverged},
+	{"circuit_open", llm.ErrCircuitOpen},
+	{"no_healthy_provider", llm.ErrNoHealthyProvider},
+}
+
+// errorKinds returns the names of the known sentinels err wraps.
+func errorKinds(err error) []string {
+	if err == nil {
+		return nil
+	}
+	var kinds []string
+	for _, k := range transcriptErrorKinds {
+		if errors.Is(err, k.err) {
+			kinds = append(kinds, k.kind)
+		}
+	}
+	return kinds
+}
+
+// recordedError is an error read back from a transcript. It keeps the
+// original message and unwraps to the sentinels named by its kinds, so that
+// errors.Is behaves as it did when the error was recorded.
+type recordedError struct {
+	msg   string
+	kinds []error
+}
+
+func (e *recordedError) Error() string   { return e.msg }
+func (e *recordedError) Unwrap() []error { return e.kinds }
+
+// err reconstructs the recorded error, or returns nil if none was recorded.
+// Unknown kinds from newer transcripts are ignored.
+func (rec *TranscriptRecord) err() error {
+	if rec.Error == "" {
+		return nil
+	}
+	e := &recordedError{msg: rec.Error}
+	for _, kind := range rec.ErrorKinds {
+		for _, k := range transcriptErrorKinds {
+			if k.kind == kind {
+				e.kinds = append(e.kinds, k.err)
+				break
+			}
+		}
+	}
+	return e
+}
@@ -0,0 +1,170 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fzihak/aethercore/core/llm"
+)
+
+// unreachableLLM fails the test if the engine ever calls it.
+type unreachableLLM struct{ t *testing.T }
+
+func (u *unreachableLLM) Name() string { return "unreachable" }
+func (u *unreachableLLM) Generate(_ context.Context, _, _ string) (string, error) {
+	u.t.Error("replay must not call the live adapter")
+	return "", errors.New("unreachable")
+}
+func (u *unreachableLLM) GenerateWithTools(_ context.Context, _ []llm.Message, _ []llm.ToolManifest) (llm.LLMResponse, error) {
+	u.t.Error("replay must not call the live adapter")
+	return llm.LLMResponse{}, errors.New("unreachable")
+}
+
+// recordIncident runs a two-iteration task and returns its transcript.
+func recordIncident(t *testing.T) []TranscriptRecord {
+	t.Helper()
+	var buf bytes.Buffer
+	adapter := &scriptedLLM{responses: []llm.LLMResponse{
+		{ToolCalls: []llm.ToolCall{{ID: "c1", Name: "count", Arguments: `{"n":1}`}}},
+		{Content: "counted once"},
+	}}
+	engine := NewEngine(adapter, 1, 1).WithTranscript(NewTranscriptWriter(&buf))
+	registerAll(t, engine, &countingTool{name: "count"})
+
+	res := runSingleTask(t, engine, &Task{ID: "incident", Input: "count", CreatedAt: time.Now()})
+	if res.Error != nil {
+		t.Fatalf("recording run failed: %v", res.Error)
+	}
+
+	records, err := ReadTranscript(&buf)
+	if err != nil {
+		t.Fatalf("ReadTranscript: %v", err)
+	}
+	return records
+}
+
+func TestTranscript_capturesFullRun(t *testing.T) {
+	records := recordIncident(t)
+
+	var types []TranscriptRecordType
+	for i := range records {
+		types = append(types, records[i].Type)
+	}
+	want := []TranscriptRecordType{
+		TranscriptTask, TranscriptGuard, TranscriptLLM, TranscriptGuard, TranscriptTool, TranscriptLLM, TranscriptResult,
+	}
+	if len(types) != len(want) {
+		t.Fatalf("want records %v, got %v", want, types)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Fatalf("want records %v, got %v", want, types)
+		}
+	}
+
+	if g := records[1].Guard; g == nil || g.Stage != GuardStageInput || !g.Safe {
+		t.Errorf("unexpected input verdict %+v", g)
+	}
+	if g := records[3].Guard; g == nil || g.Stage != GuardStageToolOutput || g.Tool != "count" {
+		t.Errorf("unexpected tool output verdict %+v", g)
+	}
+	if tool := records[4]; tool.ToolResult == nil || tool.ToolResult.Content != "count ran" {
+		t.Errorf("tool result not captured: %+v", tool)
+	}
+	if llmRec := records[5]; len(llmRec.Messages) != 4 || llmRec.Response == nil {
+		t.Errorf("second LLM call should capture the prompt and response: %+v", llmRec)
+	}
+	if final := records[6]; final.Output != "counted once" || final.Error != "" {
+		t.Errorf("unexpected result record %+v", final)
+	}
+}
+
+func TestReplay_isDeterministicAndOffline(t *testing.T) {
+	records := recordIncident(t)
+	replay, err := NewReplay(records, "incident")
+	if err != nil {
+		t.Fatalf("NewReplay: %v", err)
+	}
+
+	tool := &countingTool{name: "count"}
+	engine := NewEngine(&unreachableLLM{t: t}, 1, 1).WithReplay(replay)
+	registerAll(t, engine, tool)
+
+	res := runSingleTask(t, engine, replay.NewTask())
+	if res.Error != nil || res.Output != replay.Output {
+		t.Fatalf("replay output %q (err %v), recorded %q", res.Output, res.Error, replay.Output)
+	}
+	if tool.count() != 0 {
+		t.Errorf("replay executed the tool %d times", tool.count())
+	}
+}
+
+func TestReplay_divergenceFailsTask(t *testing.T) {
+	records := recordIncident(t)
+	// Drop the final LLM response so the loop asks for more than was recorded.
+	var truncated []TranscriptRecord
+	llmCalls := 0
+	for _, rec := range records {
+		if rec.Type == TranscriptLLM {
+			llmCalls++
+			if llmCalls == 2 {
+				continue
+			}
+		}
+		truncated = append(truncated, rec)
+	}
+	replay, err := NewReplay(truncated, "incident")
+	if err != nil {
+		t.Fatalf("NewReplay: %v", err)
+	}
+
+	res := runSingleTask(t, NewEngine(&unreachableLLM{t: t}, 1, 1).WithReplay(replay), replay.NewTask())
+	if !errors.Is(res.Error, ErrReplayDiverged) {
+		t.Fatalf("want ErrReplayDiverged, got %v", res.Error)
+	}
+
+	if _, err := NewReplay(records, "missing"); !errors.Is(err, ErrTranscriptTaskNotFound) {
+		t.Errorf("want ErrTranscriptTaskNotFound, got %v", err)
+	}
+}
+
+func TestReplay_restoresSentinelErrors(t *testing.T) {
+	records := recordIncident(t)
+	sandboxErr := fmt.Errorf("%w: dial unix: no such file", ErrSandboxUnavailable)
+	for i := range records {
+		if records[i].Type == TranscriptTool {
+			records[i].Error, records[i].ErrorKinds = errorString(sandboxErr), errorKinds(sandboxErr)
+		}
+	}
+	// Round-trip through JSONL as a real incident transcript would.
+	var buf bytes.Buffer
+	w := NewTranscriptWriter(&buf)
+	for i := range records {
+		if err := w.Record(&records[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	records, err := ReadTranscript(&buf)
+	if err != nil {
+		t.Fatalf("ReadTranscript: %v", err)
+	}
+	replay, err := NewReplay(records, "incident")
+	if err != nil {
+		t.Fatalf("NewReplay: %v", err)
+	}
+
+	engine := NewEngine(&unreachableLLM{t: t}, 1, 1).WithReplay(replay)
+	registerAll(t, engine, &countingTool{name: "count"})
+	res := runSingleTask(t, engine, replay.NewTask())
+	if !errors.Is(res.Error, ErrSandboxUnavailable) {
+		t.Fatalf("replayed tool error should abort the task as ErrSandboxUnavailable, got %v", res.Error)
+	}
+	if !strings.Contains(res.Error.Error(), "dial unix: no such file") {
+		t.Errorf("replayed error lost its message: %v", res.Error)
+	}
+}
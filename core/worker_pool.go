@@ -0,0 +1,267 @@
+package core
+
+import (
+	"errors"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+)
+
+// Inviolable Rule: Layer 0 strictly uses Go stdlib ONLY.
+
+// ErrInvalidPoolSize is returned by Resize for a size below one.
+var ErrInvalidPoolSize = errors.New("worker pool size must be at least 1")
+
+const (
+	defaultAutoscaleInterval = time.Second
+	defaultScaleUpCooldown   = 5 * time.Second
+	defaultScaleDownCooldown = 30 * time.Second
+	defaultTargetQueueWait   = 2 * time.Second
+	// latencyEWMAWeight is the weight of the newest task duration in the average.
+	latencyEWMAWeight = 0.2
+)
+
+// AutoscaleConfig bounds and paces worker pool autoscaling; see WithAutoscaling.
+type AutoscaleConfig struct {
+	Min, Max int
+	// Interval between scaling decisions. Zero means defaultAutoscaleInterval.
+	Interval time.Duration
+	// ScaleUpCooldown is the minimum time between growth steps. Zero means defaultScaleUpCooldown.
+	ScaleUpCooldown time.Duration
+	// ScaleDownCooldown is the minimum time since the last resize before the pool
+	// shrinks. Zero means defaultScaleDownCooldown.
+	ScaleDownCooldown time.Duration
+	// TargetQueueWait is how quickly the queued backlog should clear. Zero means
+	// defaultTargetQueueWait.
+	TargetQueueWait time.Duration
+}
+
+func (c *AutoscaleConfig) normalize() {
+	c.Min = max(c.Min, 1)
+	c.Max = max(c.Max, c.Min)
+	if c.Interval <= 0 {
+		c.Interval = defaultAutoscaleInterval
+	}
+	if c.ScaleUpCooldown <= 0 {
+		c.ScaleUpCooldown = defaultScaleUpCooldown
+	}
+	if c.ScaleDownCooldown <= 0 {
+		c.ScaleDownCooldown = defaultScaleDownCooldown
+	}
+	if c.TargetQueueWait <= 0 {
+		c.TargetQueueWait = defaultTargetQueueWait
+	}
+}
+
+// PoolStats is a snapshot of the worker pool.
+type PoolStats struct {
+	Workers    int // target pool size; exiting workers finish their task first
+	Busy       int // workers executing a task
+	Queued     int
+	AvgLatency time.Duration // moving average task duration
+}
+
+// workerPool tracks the engine's worker goroutines. Shrinking is cooperative:
+// surplus workers exit the next time they are idle.
+type workerPool struct {
+	mu      sync.Mutex
+	size    int // target
+	live    int // running goroutines, including those about to exit
+	excess  int // workers asked to exit but not yet gone
+	busy    int
+	nextID  int
+	started bool
+	stopped bool
+	latency float64 // EWMA of task duration, in nanoseconds
+	wake    chan struct{}
+
+	autoscale  *AutoscaleConfig
+	lastUp     time.Time
+	lastResize time.Time
+}
+
+func newWorkerPool(size int) *workerPool {
+	return &workerPool{size: max(size, 1), wake: make(chan struct{})}
+}
+
+// WithAutoscaling lets the engine grow and shrink its worker pool between
+// cfg.Min and cfg.Max. Every Interval it sizes the pool to the tasks running
+// plus enough workers for the queued backlog to clear within TargetQueueWait
+// at the average task latency, growing at most once per ScaleUpCooldown and
+// shrinking only after ScaleDownCooldown without a resize. Call before Start.
+func (e *Engine) WithAutoscaling(cfg AutoscaleConfig) *Engine {
+	cfg.normalize()
+	p := e.pool
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.autoscale = &cfg
+	p.size = min(max(p.size, cfg.Min), cfg.Max)
+	return e
+}
+
+// Resize sets the worker pool size at runtime. Growth is immediate; surplus
+// workers exit once their current task completes. With autoscaling enabled,
+// later scaling decisions may move the size again within the configured bounds.
+func (e *Engine) Resize(n int) error {
+	if n < 1 {
+		return ErrInvalidPoolSize
+	}
+	e.resize(n, "manual")
+	return nil
+}
+
+// PoolStats reports the current worker pool state.
+func (e *Engine) PoolStats() PoolStats {
+	p := e.pool
+	p.mu.Lock()
+	stats := PoolStats{Workers: p.size, Busy: p.busy, AvgLatency: time.Duration(p.latency)}
+	p.mu.Unlock()
+	e.queue.mu.Lock()
+	stats.Queued = e.queue.size
+	e.queue.mu.Unlock()
+	return stats
+}
+
+// startWorkers launches the initial pool and, if configured, the autoscaler.
+func (e *Engine) startWorkers() {
+	p := e.pool
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.started || p.stopped {
+		return
+	}
+	p.started = true
+	p.lastResize = time.Now()
+	e.spawnLocked(p.size)
+	if p.autoscale != nil {
+		e.wg.Add(1)
+		go e.autoscaler(p.autoscale.Interval)
+	}
+}
+
+// stopWorkers prevents further spawns; called before quit is closed.
+func (e *Engine) stopWorkers() {
+	e.pool.mu.Lock()
+	e.pool.stopped = true
+	e.pool.mu.Unlock()
+}
+
+// resize moves the pool to n workers, reusing pending exits before spawning.
+func (e *Engine) resize(n int, reason string) {
+	p := e.pool
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	from := p.size
+	if n == from || p.stopped {
+		return
+	}
+	p.size = n
+	p.lastResize = time.Now()
+	if n > from {
+		p.lastUp = p.lastResize
+	}
+	if p.started {
+		effective := p.live - p.excess
+		switch {
+		case n > effective:
+			kept := min(p.excess, n-effective)
+			p.excess -= kept
+			e.spawnLocked(n - effective - kept)
+		case n < effective:
+			p.excess += effective - n
+			close(p.wake) // idle workers re-check whether to exit
+			p.wake = make(chan struct{})
+		}
+	}
+	WithComponent("worker_pool").Info("worker_pool_resized",
+		slog.Int("from", from), slog.Int("to", n), slog.String("reason", reason))
+}
+
+func (e *Engine) spawnLocked(n int) {
+	for range n {
+		e.wg.Add(1)
+		e.pool.live++
+		go e.worker(e.pool.nextID)
+		e.pool.nextID++
+	}
+}
+
+// retire reports whether a worker should exit to honour a shrink, and if so
+// accounts for its departure. Otherwise it returns the channel signalling the
+// next shrink.
+func (p *workerPool) retire() (bool, <-chan struct{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.excess > 0 {
+		p.excess--
+		p.live--
+		return true, nil
+	}
+	return false, p.wake
+}
+
+// begin and end bracket task execution for utilisation and latency tracking.
+func (p *workerPool) begin() {
+	p.mu.Lock()
+	p.busy++
+	p.mu.Unlock()
+}
+
+func (p *workerPool) end(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.busy--
+	if p.latency == 0 {
+		p.latency = float64(d)
+	} else {
+		p.latency += latencyEWMAWeight * (float64(d) - p.latency)
+	}
+}
+
+// autoscaler periodically applies the scaling policy until the engine stops.
+func (e *Engine) autoscaler(interval time.Duration) {
+	defer e.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.quit:
+			return
+		case now := <-ticker.C:
+			if n, ok := e.scaleDecision(now); ok {
+				e.resize(n, "autoscale")
+			}
+		}
+	}
+}
+
+// scaleDecision returns the pool size the autoscaling policy wants, if it
+// differs from the current size and the relevant cooldown has elapsed.
+func (e *Engine) scaleDecision(now time.Time) (int, bool) {
+	e.queue.mu.Lock()
+	queued := e.queue.size
+	e.queue.mu.Unlock()
+
+	p := e.pool
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	cfg := p.autoscale
+
+	backlog := queued
+	if p.latency > 0 {
+		// Workers needed for the backlog to clear within the target wait.
+		need := math.Ceil(float64(queued) * p.latency / float64(cfg.TargetQueueWait))
+		backlog = min(queued, int(need))
+	}
+	desired := min(max(p.busy+backlog, cfg.Min), cfg.Max)
+
+	switch {
+	case desired > p.size && now.Sub(p.lastUp) >= cfg.ScaleUpCooldown:
+		return desired, true
+	case desired < p.size && now.Sub(p.lastResize) >= cfg.ScaleDownCooldown:
+		return desired, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestResize_growsAndShrinksPool(t *testing.T) {
+	adapter := newBlockingLLM()
+	engine := NewEngine(adapter, 1, 8)
+	engine.Start()
+	defer engine.Stop()
+
+	if err := engine.Resize(0); !errors.Is(err, ErrInvalidPoolSize) {
+		t.Fatalf("want ErrInvalidPoolSize, got %v", err)
+	}
+	if err := engine.Resize(3); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		if err := engine.Submit(&Task{ID: id, Input: "go"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for range 3 { // all three run concurrently on the grown pool
+		select {
+		case <-adapter.calls:
+		case <-time.After(2 * time.Second):
+			t.Fatal("pool did not grow to 3 concurrent workers")
+		}
+	}
+	if stats := engine.PoolStats(); stats.Workers != 3 || stats.Busy != 3 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	if err := engine.Resize(1); err != nil {
+		t.Fatal(err)
+	}
+	close(adapter.release)
+	for range 3 { // shrinking never interrupts running tasks
+		if res := <-engine.Results(); res.Error != nil {
+			t.Errorf("%s: %v", res.TaskID, res.Error)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		engine.pool.mu.Lock()
+		live := engine.pool.live
+		engine.pool.mu.Unlock()
+		if live == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("want 1 live worker after shrink, got %d", live)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestScaleDecision_followsBacklogWithCooldowns(t *testing.T) {
+	engine := NewEngine(&scriptedLLM{}, 1, 16).WithAutoscaling(AutoscaleConfig{
+		Min: 1, Max: 4, ScaleUpCooldown: time.Second, ScaleDownCooldown: time.Minute, TargetQueueWait: 2 * time.Second,
+	})
+	start := time.Now()
+	p := engine.pool
+	p.lastUp, p.lastResize = start, start
+	p.latency = float64(time.Second)
+	engine.queue.size = 6 // ceil(6 * 1s / 2s) = 3 workers to clear the backlog in time
+
+	if _, ok := engine.scaleDecision(start.Add(500 * time.Millisecond)); ok {
+		t.Fatal("scaled up within the cooldown")
+	}
+	if n, ok := engine.scaleDecision(start.Add(time.Second)); !ok || n != 3 {
+		t.Fatalf("want scale to 3, got %d (%v)", n, ok)
+	}
+
+	engine.queue.size = 40
+	if n, _ := engine.scaleDecision(start.Add(time.Second)); n != 4 {
+		t.Fatalf("want scale capped at Max 4, got %d", n)
+	}
+
+	p.size, engine.queue.size = 4, 0
+	if _, ok := engine.scaleDecision(start.Add(time.Second)); ok {
+		t.Fatal("scaled down within the cooldown")
+	}
+	if n, ok := engine.scaleDecision(start.Add(time.Minute)); !ok || n != 1 {
+		t.Fatalf("want scale down to Min 1, got %d (%v)", n, ok)
+	}
+}